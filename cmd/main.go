@@ -7,16 +7,21 @@ import (
 	"os"
 	"uno/internal/command"
 	"uno/internal/load"
+
+	"golang.org/x/term"
 )
 
 func main() {
-	m := load.Model{
-		Progress: progress.New(progress.WithDefaultGradient()),
-	}
+	// Заставка только в интерактивном терминале, чтобы не портить вывод в пайпы
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		m := load.Model{
+			Progress: progress.New(progress.WithDefaultGradient()),
+		}
 
-	if _, err := tea.NewProgram(m).Run(); err != nil {
-		fmt.Println("Oh no!", err)
-		os.Exit(1)
+		if _, err := tea.NewProgram(m).Run(); err != nil {
+			fmt.Println("Oh no!", err)
+			os.Exit(1)
+		}
 	}
 	command.Execute()
 }
@@ -0,0 +1,85 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"uno/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config файл правил алертинга
+//
+//	rules:
+//	  - name: high-memory
+//	    expr: mem.used_pct > 90 for 1m
+//	    severity: critical
+//	sinks:
+//	  - type: webhook
+//	    url: http://localhost:9000/alerts
+type Config struct {
+	Rules []RuleConfig `yaml:"rules"`
+	Sinks []SinkConfig `yaml:"sinks"`
+}
+
+// RuleConfig правило: имя, условие и важность (warning по умолчанию)
+type RuleConfig struct {
+	Name     string `yaml:"name"`
+	Expr     string `yaml:"expr"`
+	Severity string `yaml:"severity"`
+}
+
+// SinkConfig получатель алертов: stderr (устаревшее имя stdout), file, command или webhook
+type SinkConfig struct {
+	Type    string `yaml:"type"`
+	Path    string `yaml:"path"`
+	Command string `yaml:"command"`
+	URL     string `yaml:"url"`
+}
+
+// DefaultConfigPath путь к конфигу правил, если --alerts не задан
+func DefaultConfigPath() string {
+	dir := config.Dir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "alerts.yaml")
+}
+
+// LoadConfig читает и разбирает YAML-файл правил
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alerts config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse alerts config %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Load создает движок из файла правил. Пустой path означает конфиг по умолчанию;
+// если его нет, возвращается nil - алертинг выключен
+func Load(path string) (*Engine, error) {
+	if path == "" {
+		path = DefaultConfigPath()
+		if path == "" {
+			return nil, nil
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEngine(cfg)
+}
@@ -0,0 +1,279 @@
+package alerts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Состояния алерта, передаваемые получателям
+const (
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// Alert сработавшее или погасшее правило
+type Alert struct {
+	Rule     string    `json:"rule"`
+	Expr     string    `json:"expr"`
+	Severity string    `json:"severity"`
+	State    string    `json:"state"`
+	Value    float64   `json:"value"`
+	Since    time.Time `json:"since"`
+	At       time.Time `json:"at"`
+}
+
+// rule разобранное правило и его состояние между тиками
+type rule struct {
+	RuleConfig
+	cond *Condition
+
+	pending time.Time // когда условие стало истинным
+	firing  bool
+	value   float64
+}
+
+// sinkQueueSize сколько алертов может ждать доставки одним получателем
+const sinkQueueSize = 64
+
+// namedSink получатель, его имя для сообщений об ошибках доставки и очередь алертов
+type namedSink struct {
+	name  string
+	queue chan Alert
+	Sink
+}
+
+// Engine вычисляет правила на каждом тике и уведомляет получателей о смене состояния
+type Engine struct {
+	mu       sync.Mutex
+	rules    []*rule
+	sinks    []namedSink
+	sinkErrs map[string]error // ошибка последней доставки по имени получателя
+	now      func() time.Time
+}
+
+// NewEngine разбирает правила и создает получателей из конфига
+func NewEngine(cfg *Config) (*Engine, error) {
+	e := &Engine{now: time.Now, sinkErrs: make(map[string]error)}
+
+	for i, rc := range cfg.Rules {
+		if rc.Name == "" {
+			rc.Name = fmt.Sprintf("rule%d", i+1)
+		}
+		if rc.Severity == "" {
+			rc.Severity = "warning"
+		}
+
+		cond, err := ParseCondition(rc.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %s: %w", rc.Name, err)
+		}
+		e.rules = append(e.rules, &rule{RuleConfig: rc, cond: cond})
+	}
+
+	for _, sc := range cfg.Sinks {
+		sink, err := NewSink(sc)
+		if err != nil {
+			return nil, err
+		}
+		e.addSink(sinkName(sc), sink)
+	}
+
+	return e, nil
+}
+
+// Evaluate проверяет правила на наборе метрик. Правила, метрик которых нет в наборе,
+// пропускаются без смены состояния: так один конфиг подходит и для хоста, и для БД, и для логов
+func (e *Engine) Evaluate(metrics map[string]float64) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	for _, r := range e.rules {
+		value, matched, ok := r.cond.Eval(metrics)
+		if !ok {
+			continue
+		}
+		r.value = value
+
+		if !matched {
+			if r.firing {
+				e.notify(r.alert(StateResolved, now))
+			}
+			r.pending = time.Time{}
+			r.firing = false
+			continue
+		}
+
+		if r.pending.IsZero() {
+			r.pending = now
+		}
+		if !r.firing && now.Sub(r.pending) >= r.cond.For {
+			r.firing = true
+			e.notify(r.alert(StateFiring, now))
+		}
+	}
+}
+
+// Active возвращает сработавшие правила, самые важные первыми
+func (e *Engine) Active() []Alert {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var active []Alert
+	now := e.now()
+	for _, r := range e.rules {
+		if r.firing {
+			active = append(active, r.alert(StateFiring, now))
+		}
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return severityRank(active[i].Severity) > severityRank(active[j].Severity)
+	})
+	return active
+}
+
+// SinkErrors ошибки последней доставки по получателям; успешно доставившие в карту не попадают
+func (e *Engine) SinkErrors() map[string]error {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	errs := make(map[string]error, len(e.sinkErrs))
+	for name, err := range e.sinkErrs {
+		errs[name] = err
+	}
+	return errs
+}
+
+// addSink подключает получателя с собственной горутиной доставки: алерты одного получателя
+// доставляются по очереди, и resolved не может обогнать firing того же правила
+func (e *Engine) addSink(name string, sink Sink) {
+	ns := namedSink{name: name, queue: make(chan Alert, sinkQueueSize), Sink: sink}
+	e.sinks = append(e.sinks, ns)
+
+	go func() {
+		for alert := range ns.queue {
+			e.setSinkError(ns.name, ns.Notify(alert))
+		}
+	}()
+}
+
+// setSinkError запоминает результат последней доставки получателю
+func (e *Engine) setSinkError(name string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		e.sinkErrs[name] = err
+	} else {
+		delete(e.sinkErrs, name)
+	}
+}
+
+// notify ставит алерт в очереди получателей, чтобы медленный webhook не тормозил тик.
+// Вызывается под e.mu, поэтому не ждет: если очередь получателя полна, алерт теряется с ошибкой
+func (e *Engine) notify(alert Alert) {
+	for _, sink := range e.sinks {
+		select {
+		case sink.queue <- alert:
+		default:
+			e.sinkErrs[sink.name] = fmt.Errorf("delivery queue is full, %s %s dropped", alert.Rule, alert.State)
+		}
+	}
+}
+
+// sinkName имя получателя для баннера: тип и адрес доставки
+func sinkName(cfg SinkConfig) string {
+	switch cfg.Type {
+	case "file":
+		return "file " + cfg.Path
+	case "command":
+		return "command " + cfg.Command
+	case "webhook":
+		return "webhook " + cfg.URL
+	case "stdout":
+		return "stderr"
+	default:
+		return cfg.Type
+	}
+}
+
+func (r *rule) alert(state string, now time.Time) Alert {
+	return Alert{
+		Rule:     r.Name,
+		Expr:     r.Expr,
+		Severity: r.Severity,
+		State:    state,
+		Value:    r.value,
+		Since:    r.pending,
+		At:       now,
+	}
+}
+
+func severityRank(severity string) int {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return 2
+	case "warning":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Banner строка для TUI с активными алертами; пустая, если алертов нет
+func (e *Engine) Banner() string {
+	active := e.Active()
+	sinkErrs := e.SinkErrors()
+	if len(active) == 0 && len(sinkErrs) == 0 {
+		return ""
+	}
+
+	criticalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#CC0000")).
+		Bold(true)
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#000000")).
+		Background(lipgloss.Color("#FFAA00")).
+		Bold(true)
+
+	var lines []string
+	for _, a := range active {
+		style := warningStyle
+		if severityRank(a.Severity) > 1 {
+			style = criticalStyle
+		}
+		lines = append(lines, style.Render(fmt.Sprintf(" ALERT %s: %s (%.4g, %s) ",
+			a.Rule, a.Expr, a.Value, a.At.Sub(a.Since).Truncate(time.Second))))
+	}
+
+	// Ошибки каждого получателя отдельной строкой, в стабильном порядке между тиками
+	names := make([]string, 0, len(sinkErrs))
+	for name := range sinkErrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	for _, name := range names {
+		lines = append(lines, errorStyle.Render(fmt.Sprintf("alert delivery to %s failed: %v", name, sinkErrs[name])))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,307 @@
+package alerts
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCondition(t *testing.T) {
+	metrics := map[string]float64{
+		"db.active_connections": 90,
+		"db.max_connections":    100,
+		"logs.error_rate":       0.5,
+		"mem.used_pct":          42,
+	}
+
+	tests := []struct {
+		expr    string
+		value   float64
+		matched bool
+		ok      bool
+		dur     time.Duration
+	}{
+		{"mem.used_pct > 40", 42, true, true, 0},
+		{"mem.used_pct >= 42", 42, true, true, 0},
+		{"mem.used_pct < 42", 42, false, true, 0},
+		{"mem.used_pct <= 41.5", 42, false, true, 0},
+		{"mem.used_pct == 42", 42, true, true, 0},
+		{"mem.used_pct != 42", 42, false, true, 0},
+		{"db.active_connections / db.max_connections > 0.8", 0.9, true, true, 0},
+		{"(db.active_connections + 10) * 2 > 150", 200, true, true, 0},
+		{"-mem.used_pct < 0", -42, true, true, 0},
+		{"1 + 2 * 3 == 7", 7, true, true, 0},
+		{"logs.error_rate > 10/min", 0.5, true, true, 0},
+		{"logs.error_rate > 60/h", 0.5, true, true, 0},
+		{"mem.used_pct > 90 for 1m", 42, false, true, time.Minute},
+		{"cpu.used_pct > 90", 0, false, false, 0},
+		{"mem.used_pct / 0 > 1", 0, false, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := ParseCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCondition: %v", err)
+			}
+			if cond.For != tt.dur {
+				t.Errorf("For = %v, want %v", cond.For, tt.dur)
+			}
+
+			value, matched, ok := cond.Eval(metrics)
+			if ok != tt.ok || matched != tt.matched || math.Abs(value-tt.value) > 1e-9 {
+				t.Errorf("Eval() = %v, %v, %v; want %v, %v, %v", value, matched, ok, tt.value, tt.matched, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"mem.used_pct",
+		"mem.used_pct > ",
+		"mem.used_pct = 1",
+		"mem.used_pct > 1 2",
+		"(mem.used_pct > 1",
+		"mem.used_pct > 1 for soon",
+		"mem.used_pct > 1 ^ 2",
+	} {
+		if _, err := ParseCondition(expr); err == nil {
+			t.Errorf("ParseCondition(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+// recordingSink собирает уведомления; Notify вызывается из горутин движка
+type recordingSink struct {
+	alerts chan Alert
+}
+
+func (s *recordingSink) Notify(alert Alert) error {
+	s.alerts <- alert
+	return nil
+}
+
+// expect ждет следующее уведомление и проверяет его правило и состояние
+func (s *recordingSink) expect(t *testing.T, rule, state string) {
+	t.Helper()
+	select {
+	case alert := <-s.alerts:
+		if alert.Rule != rule || alert.State != state {
+			t.Errorf("got %s %s, want %s %s", alert.Rule, alert.State, rule, state)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("no notification, want %s %s", rule, state)
+	}
+}
+
+// expectNone проверяет, что уведомлений не было
+func (s *recordingSink) expectNone(t *testing.T) {
+	t.Helper()
+	select {
+	case alert := <-s.alerts:
+		t.Errorf("unexpected notification %s %s", alert.Rule, alert.State)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// newTestEngine движок с одним правилом, ручными часами и записывающим получателем
+func newTestEngine(t *testing.T, expr string) (*Engine, *recordingSink, *time.Time) {
+	t.Helper()
+
+	e, err := NewEngine(&Config{Rules: []RuleConfig{{Name: "test", Expr: expr}}})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	sink := &recordingSink{alerts: make(chan Alert, 16)}
+	e.addSink("recording", sink)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+	return e, sink, &now
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	type step struct {
+		after  time.Duration // сдвиг часов перед проверкой
+		value  float64       // значение метрики; NaN - метрики нет в наборе
+		state  string        // ожидаемое уведомление; пусто - без уведомления
+		firing bool          // правило активно после шага
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		steps []step
+	}{
+		{
+			name: "fires and resolves immediately without for",
+			expr: "load > 10",
+			steps: []step{
+				{0, 5, "", false},
+				{time.Second, 11, StateFiring, true},
+				{time.Second, 12, "", true}, // повторно не уведомляет
+				{time.Second, 9, StateResolved, false},
+				{time.Second, 8, "", false},
+			},
+		},
+		{
+			name: "for waits until the condition holds long enough",
+			expr: "load > 10 for 1m",
+			steps: []step{
+				{0, 11, "", false},
+				{30 * time.Second, 11, "", false},
+				{30 * time.Second, 11, StateFiring, true},
+				{time.Minute, 11, "", true},
+				{time.Second, 1, StateResolved, false},
+			},
+		},
+		{
+			name: "flapping resets the for timer",
+			expr: "load > 10 for 1m",
+			steps: []step{
+				{0, 11, "", false},
+				{50 * time.Second, 1, "", false},
+				{5 * time.Second, 11, "", false},
+				{50 * time.Second, 11, "", false},
+				{10 * time.Second, 11, StateFiring, true},
+			},
+		},
+		{
+			name: "missing metric keeps the state",
+			expr: "load > 10",
+			steps: []step{
+				{0, 11, StateFiring, true},
+				{time.Second, math.NaN(), "", true},
+				{time.Second, math.NaN(), "", true},
+				{time.Second, 1, StateResolved, false},
+				{time.Second, math.NaN(), "", false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, sink, now := newTestEngine(t, tt.expr)
+
+			for i, s := range tt.steps {
+				*now = now.Add(s.after)
+				metrics := map[string]float64{}
+				if !math.IsNaN(s.value) {
+					metrics["load"] = s.value
+				}
+				e.Evaluate(metrics)
+
+				if s.state == "" {
+					sink.expectNone(t)
+				} else {
+					sink.expect(t, "test", s.state)
+				}
+				if firing := len(e.Active()) > 0; firing != s.firing {
+					t.Errorf("step %d: firing = %v, want %v", i, firing, s.firing)
+				}
+			}
+		})
+	}
+}
+
+func TestEngineActiveOrderAndSince(t *testing.T) {
+	e, err := NewEngine(&Config{Rules: []RuleConfig{
+		{Name: "warn", Expr: "load > 1"},
+		{Name: "crit", Expr: "load > 2 for 10s", Severity: "critical"},
+	}})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	e.now = func() time.Time { return now }
+
+	e.Evaluate(map[string]float64{"load": 5})
+	now = now.Add(15 * time.Second)
+	e.Evaluate(map[string]float64{"load": 5})
+
+	active := e.Active()
+	if len(active) != 2 || active[0].Rule != "crit" || active[1].Rule != "warn" {
+		t.Fatalf("Active() = %+v, want crit before warn", active)
+	}
+	if !active[0].Since.Equal(start) || active[0].At.Sub(active[0].Since) != 15*time.Second {
+		t.Errorf("crit: Since = %v, At = %v", active[0].Since, active[0].At)
+	}
+	if !strings.Contains(e.Banner(), "ALERT crit") {
+		t.Errorf("Banner() = %q", e.Banner())
+	}
+}
+
+func TestNilEngine(t *testing.T) {
+	var e *Engine
+	e.Evaluate(map[string]float64{"load": 1})
+	if e.Active() != nil || e.SinkErrors() != nil || e.Banner() != "" {
+		t.Error("nil engine must be a no-op")
+	}
+}
+
+// slowSink задерживает первую доставку, чтобы следующий алерт мог ее обогнать, если бы
+// доставки шли в отдельных горутинах
+type slowSink struct {
+	recordingSink
+	delayed bool
+}
+
+func (s *slowSink) Notify(alert Alert) error {
+	if !s.delayed {
+		s.delayed = true
+		time.Sleep(50 * time.Millisecond)
+	}
+	return s.recordingSink.Notify(alert)
+}
+
+func TestEngineDeliversInOrderPerSink(t *testing.T) {
+	e, err := NewEngine(&Config{Rules: []RuleConfig{{Name: "test", Expr: "load > 10"}}})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	sink := &slowSink{recordingSink: recordingSink{alerts: make(chan Alert, 16)}}
+	e.addSink("slow", sink)
+
+	for i := 0; i < 3; i++ {
+		e.Evaluate(map[string]float64{"load": 11})
+		e.Evaluate(map[string]float64{"load": 1})
+	}
+	for i := 0; i < 3; i++ {
+		sink.expect(t, "test", StateFiring)
+		sink.expect(t, "test", StateResolved)
+	}
+}
+
+func TestEngineReportsFullQueue(t *testing.T) {
+	e, err := NewEngine(&Config{Rules: []RuleConfig{{Name: "test", Expr: "load > 10"}}})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	block := make(chan struct{})
+	defer close(block)
+	e.addSink("stuck", sinkFunc(func(Alert) error {
+		<-block
+		return nil
+	}))
+
+	// Первый алерт забирает горутина доставки, следующие заполняют очередь
+	for i := 0; i <= sinkQueueSize; i++ {
+		e.Evaluate(map[string]float64{"load": 11})
+		e.Evaluate(map[string]float64{"load": 1})
+	}
+
+	if err := e.SinkErrors()["stuck"]; err == nil || !strings.Contains(err.Error(), "queue is full") {
+		t.Errorf("SinkErrors()[stuck] = %v, want full queue", err)
+	}
+}
+
+// sinkFunc получатель из функции
+type sinkFunc func(Alert) error
+
+func (f sinkFunc) Notify(alert Alert) error {
+	return f(alert)
+}
@@ -0,0 +1,291 @@
+package alerts
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// units константы, которые можно использовать в выражениях: "10/min" - десять в минуту в пересчете на секунду
+var units = map[string]float64{
+	"s":   1,
+	"sec": 1,
+	"min": 60,
+	"h":   3600,
+}
+
+// node узел арифметического выражения
+type node interface {
+	eval(metrics map[string]float64) (float64, bool)
+}
+
+type number float64
+
+func (n number) eval(map[string]float64) (float64, bool) {
+	return float64(n), true
+}
+
+type ident string
+
+// eval возвращает значение метрики; если ее нет, пробует единицу измерения
+func (i ident) eval(metrics map[string]float64) (float64, bool) {
+	if v, ok := metrics[string(i)]; ok {
+		return v, true
+	}
+	if v, ok := units[string(i)]; ok {
+		return v, true
+	}
+	return 0, false
+}
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b binary) eval(metrics map[string]float64) (float64, bool) {
+	l, ok := b.left.eval(metrics)
+	if !ok {
+		return 0, false
+	}
+	r, ok := b.right.eval(metrics)
+	if !ok {
+		return 0, false
+	}
+
+	switch b.op {
+	case '+':
+		return l + r, true
+	case '-':
+		return l - r, true
+	case '*':
+		return l * r, true
+	default:
+		if r == 0 {
+			return 0, false
+		}
+		return l / r, true
+	}
+}
+
+type negate struct {
+	operand node
+}
+
+func (n negate) eval(metrics map[string]float64) (float64, bool) {
+	v, ok := n.operand.eval(metrics)
+	return -v, ok
+}
+
+// Condition разобранное условие правила: left <op> right [for duration]
+type Condition struct {
+	left  node
+	op    string
+	right node
+	For   time.Duration
+}
+
+var forClause = regexp.MustCompile(`^(.*?)\s+for\s+(\S+)\s*$`)
+
+// ParseCondition разбирает выражение вида "db.active_connections / db.max_connections > 0.8 for 1m"
+func ParseCondition(expr string) (*Condition, error) {
+	cond := &Condition{}
+
+	if m := forClause.FindStringSubmatch(expr); m != nil {
+		d, err := time.ParseDuration(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", m[2], err)
+		}
+		cond.For = d
+		expr = m[1]
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	cond.left, err = p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.next()
+	switch op {
+	case ">", ">=", "<", "<=", "==", "!=":
+		cond.op = op
+	default:
+		return nil, fmt.Errorf("expected comparison operator, got %q", op)
+	}
+
+	cond.right, err = p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	return cond, nil
+}
+
+// Eval вычисляет условие; ok=false, если каких-то метрик нет в наборе
+func (c *Condition) Eval(metrics map[string]float64) (value float64, matched bool, ok bool) {
+	l, ok := c.left.eval(metrics)
+	if !ok {
+		return 0, false, false
+	}
+	r, ok := c.right.eval(metrics)
+	if !ok {
+		return 0, false, false
+	}
+
+	switch c.op {
+	case ">":
+		matched = l > r
+	case ">=":
+		matched = l >= r
+	case "<":
+		matched = l < r
+	case "<=":
+		matched = l <= r
+	case "==":
+		matched = l == r
+	case "!=":
+		matched = l != r
+	}
+
+	return l, matched, true
+}
+
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case strings.ContainsRune("<>=!", r):
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else if r == '<' || r == '>' {
+				tokens = append(tokens, string(r))
+				i++
+			} else {
+				return nil, fmt.Errorf("unexpected %q", string(r))
+			}
+		case strings.ContainsRune("+-*/()", r):
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q", string(r))
+		}
+	}
+
+	return tokens, nil
+}
+
+// parser рекурсивный спуск: expr = term {(+|-) term}, term = factor {(*|/) factor}
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	if t != "" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.next()[0]
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" || p.peek() == "/" {
+		op := p.next()[0]
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) factor() (node, error) {
+	t := p.next()
+	switch {
+	case t == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case t == "(":
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		return n, nil
+	case t == "-":
+		n, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return negate{operand: n}, nil
+	case unicode.IsDigit(rune(t[0])) || t[0] == '.':
+		v, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t)
+		}
+		return number(v), nil
+	case unicode.IsLetter(rune(t[0])) || t[0] == '_':
+		return ident(t), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", t)
+	}
+}
@@ -0,0 +1,149 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// webhookTimeout ограничивает время доставки одного алерта по HTTP
+const webhookTimeout = 5 * time.Second
+
+// Sink получатель алертов
+type Sink interface {
+	Notify(alert Alert) error
+}
+
+// NewSink создает получателя по конфигу
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "stderr", "stdout": // stdout - устаревшее имя: stdout занят TUI
+		// Все команды с алертами - TUI: строки в общем терминале ломают его отрисовку
+		if term.IsTerminal(int(os.Stderr.Fd())) {
+			return nil, fmt.Errorf("%s sink writes to stderr, which is the TUI terminal: redirect it (2>>alerts.log) or use the file sink", cfg.Type)
+		}
+		return &StderrSink{Out: os.Stderr}, nil
+	case "file":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file sink requires path")
+		}
+		return &FileSink{Path: cfg.Path}, nil
+	case "command":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("command sink requires command")
+		}
+		return &CommandSink{Command: cfg.Command}, nil
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook sink requires url")
+		}
+		return &WebhookSink{URL: cfg.URL, Client: &http.Client{Timeout: webhookTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type: %q", cfg.Type)
+	}
+}
+
+// StderrSink печатает алерты одной строкой в Out; NewSink направляет его в stderr
+type StderrSink struct {
+	Out io.Writer
+
+	mu sync.Mutex
+}
+
+func (s *StderrSink) Notify(alert Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.Out, "[%s] %s %s: %s = %.4g\n",
+		alert.At.Format(time.RFC3339), alert.State, alert.Rule, alert.Expr, alert.Value)
+	return err
+}
+
+// FileSink дописывает алерты в файл в формате NDJSON
+type FileSink struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *FileSink) Notify(alert Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open alerts file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write alerts file: %w", err)
+	}
+	return nil
+}
+
+// CommandSink запускает команду через sh -c; алерт передается в stdin как JSON
+// и в переменных окружения UNO_ALERT_*
+type CommandSink struct {
+	Command string
+}
+
+func (s *CommandSink) Notify(alert Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	cmd := exec.Command("sh", "-c", s.Command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(),
+		"UNO_ALERT_NAME="+alert.Rule,
+		"UNO_ALERT_EXPR="+alert.Expr,
+		"UNO_ALERT_SEVERITY="+alert.Severity,
+		"UNO_ALERT_STATE="+alert.State,
+		"UNO_ALERT_VALUE="+strconv.FormatFloat(alert.Value, 'g', -1, 64),
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("alert command failed: %w: %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
+
+// WebhookSink отправляет алерт POST-запросом с JSON-телом
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *WebhookSink) Notify(alert Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
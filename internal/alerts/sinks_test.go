@@ -0,0 +1,142 @@
+package alerts
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var alert Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		received <- alert
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(SinkConfig{Type: "webhook", URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	alert := Alert{
+		Rule:     "high-memory",
+		Expr:     "mem.used_pct > 90",
+		Severity: "critical",
+		State:    StateFiring,
+		Value:    95.5,
+		Since:    at.Add(-time.Minute),
+		At:       at,
+	}
+	if err := sink.Notify(alert); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	got := <-received
+	if got.Rule != alert.Rule || got.State != alert.State || got.Value != alert.Value || !got.At.Equal(alert.At) {
+		t.Errorf("webhook received %+v, want %+v", got, alert)
+	}
+}
+
+func TestWebhookSinkErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(SinkConfig{Type: "webhook", URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+	if err := sink.Notify(Alert{Rule: "r", State: StateFiring}); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Notify() error = %v, want 500 status", err)
+	}
+}
+
+func TestEngineReportsErrorsPerSink(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	delivered := make(chan struct{}, 1)
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer working.Close()
+
+	e, err := NewEngine(&Config{
+		Rules: []RuleConfig{{Name: "load", Expr: "load > 1"}},
+		Sinks: []SinkConfig{
+			{Type: "webhook", URL: failing.URL},
+			{Type: "webhook", URL: working.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	e.Evaluate(map[string]float64{"load": 2})
+	<-delivered
+
+	// Доставки идут в фоне: ждем, пока ошибка неработающего получателя появится в движке
+	deadline := time.Now().Add(time.Second)
+	for len(e.SinkErrors()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	errs := e.SinkErrors()
+	if len(errs) != 1 || errs["webhook "+failing.URL] == nil {
+		t.Fatalf("SinkErrors() = %v, want only the failing webhook", errs)
+	}
+	if banner := e.Banner(); !strings.Contains(banner, "alert delivery to webhook "+failing.URL+" failed") {
+		t.Errorf("Banner() = %q", banner)
+	}
+}
+
+func TestNewSinkValidation(t *testing.T) {
+	for _, cfg := range []SinkConfig{
+		{Type: "file"},
+		{Type: "command"},
+		{Type: "webhook"},
+		{Type: "pager"},
+	} {
+		if _, err := NewSink(cfg); err == nil {
+			t.Errorf("NewSink(%+v) succeeded, want error", cfg)
+		}
+	}
+}
+
+func TestStderrSinkAliases(t *testing.T) {
+	for _, typ := range []string{"stderr", "stdout"} {
+		if got := sinkName(SinkConfig{Type: typ}); got != "stderr" {
+			t.Errorf("sinkName(%s) = %q, want stderr", typ, got)
+		}
+	}
+}
+
+func TestStderrSinkFormat(t *testing.T) {
+	var out strings.Builder
+	sink := &StderrSink{Out: &out}
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := sink.Notify(Alert{Rule: "load", Expr: "load > 1", State: StateFiring, Value: 2.5, At: at}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if want := "[2025-01-01T12:00:00Z] firing load: load > 1 = 2.5\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
@@ -2,10 +2,18 @@ package command
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"time"
+	"uno/internal/alerts"
+	"uno/internal/config"
 	"uno/internal/database"
+	"uno/internal/exporter"
 	"uno/internal/httpR"
 	"uno/internal/logs"
+	"uno/internal/table"
 	"uno/internal/teas"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,10 +27,28 @@ var rootCmd = &cobra.Command{
 
 var monitorCmd = &cobra.Command{
 	Use:   "monitor",
-	Short: "Live RAM monitor (TUI)",
+	Short: "Live system monitor: CPU, RAM, disk, network, processes (TUI)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		p := tea.NewProgram(teas.Model{})
-		_, err := p.Run()
+		output, _ := cmd.Flags().GetString("output")
+		once, _ := cmd.Flags().GetBool("once")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		// Без TUI: один снимок или поток снимков в stdout
+		if output != "" || once {
+			if output == "" {
+				output = "json"
+			}
+			return teas.RunHeadless(os.Stdout, output, interval, once)
+		}
+
+		window, _ := cmd.Flags().GetDuration("window")
+		diskThreshold, _ := cmd.Flags().GetFloat64("disk-threshold")
+		engine, err := loadAlerts(cmd)
+		if err != nil {
+			return err
+		}
+		p := tea.NewProgram(teas.NewModel(window).WithDiskThreshold(diskThreshold).WithAlerts(engine))
+		_, err = p.Run()
 		return err
 	},
 }
@@ -45,15 +71,302 @@ var logsCmd = &cobra.Command{
 		containerID := args[0]
 		errorsOnly, _ := cmd.Flags().GetBool("err")
 		tail, _ := cmd.Flags().GetInt("tail")
-		return logs.RunLogs(containerID, errorsOnly, tail)
+		engine, err := loadAlerts(cmd)
+		if err != nil {
+			return err
+		}
+		return logs.RunLogs(containerID, errorsOnly, tail, engine)
 	},
 }
 
+// loadAlerts загружает правила из --alerts или из конфига по умолчанию; nil, если правил нет
+func loadAlerts(cmd *cobra.Command) (*alerts.Engine, error) {
+	path, _ := cmd.Flags().GetString("alerts")
+	engine, err := alerts.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alerts: %w", err)
+	}
+	return engine, nil
+}
+
 func init() {
+	rootCmd.PersistentFlags().String("alerts", "", "Alert rules file (default ~/.config/uno/alerts.yaml if present)")
+	rootCmd.PersistentFlags().String("config", "", "Config file with connection profiles (default ~/.config/uno/config.yaml)")
+	rootCmd.PersistentFlags().Bool("password-stdin", false, "Read the database password from the first line of stdin")
+	rootCmd.PersistentFlags().BoolP("password-prompt", "W", false, "Prompt for the database password")
+
+	monitorCmd.Flags().DurationP("window", "w", teas.DefaultWindow, "History window for sparklines and min/avg/max")
+	monitorCmd.Flags().StringP("output", "o", "", "Print metrics without TUI: json or ndjson")
+	monitorCmd.Flags().Bool("once", false, "Print a single snapshot and exit")
+	monitorCmd.Flags().DurationP("interval", "i", time.Second, "Interval between snapshots in headless mode")
+	monitorCmd.Flags().Float64("disk-threshold", teas.DefaultDiskThreshold, "Highlight filesystems filled above this percent")
+
 	logsCmd.Flags().BoolP("err", "e", false, "Show only error logs")
 	logsCmd.Flags().IntP("tail", "t", 0, "Number of log lines to show (0 = all logs)")
 }
 
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Expose metrics over HTTP",
+	Long: `Expose host, database and Docker metrics over HTTP.
+
+Databases are added with --db [name=]profile_or_connection_string; the name becomes the "db" label.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		metrics, _ := cmd.Flags().GetBool("metrics")
+		listen, _ := cmd.Flags().GetString("listen")
+		dbs, _ := cmd.Flags().GetStringArray("db")
+		docker, _ := cmd.Flags().GetBool("docker")
+
+		if !metrics {
+			return fmt.Errorf("nothing to serve: enable --metrics")
+		}
+
+		monitors := make(map[string]database.DBMonitor)
+		for i, spec := range dbs {
+			name, ref := splitNamedConnection(spec, i)
+
+			monitor, err := openMonitor(cmd, ref)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			defer monitor.Close()
+
+			monitors[name] = monitor
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", &exporter.Exporter{
+			Collector: teas.NewCollector(),
+			Monitors:  monitors,
+			Docker:    docker,
+		})
+
+		fmt.Printf("Метрики доступны на http://%s/metrics\n", listen)
+		return http.ListenAndServe(listen, mux)
+	},
+}
+
+// splitNamedConnection разбирает "name=connection_string". Без имени метка берется из имени профиля,
+// а для строки подключения используется db<index>
+func splitNamedConnection(spec string, index int) (string, string) {
+	if idx := strings.Index(spec, "="); idx > 0 && !strings.ContainsAny(spec[:idx], ":/@?") {
+		return spec[:idx], spec[idx+1:]
+	}
+	if !strings.Contains(spec, "://") && !strings.HasPrefix(spec, "file:") {
+		return spec, spec
+	}
+	return fmt.Sprintf("db%d", index), spec
+}
+
+func init() {
+	serveCmd.Flags().Bool("metrics", false, "Serve Prometheus metrics at /metrics")
+	serveCmd.Flags().StringP("listen", "l", ":9477", "Address to listen on")
+	serveCmd.Flags().StringArray("db", nil, "Database to export, as [name=]profile_or_connection_string (repeatable)")
+	serveCmd.Flags().Bool("docker", true, "Export Docker container states")
+}
+
+// profileLoader создает загрузчик профилей подключения с учетом --config и флагов пароля
+func profileLoader(cmd *cobra.Command) (*config.Loader, error) {
+	path, _ := cmd.Flags().GetString("config")
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := config.NewLoader(cfg)
+	loader.PasswordStdin, _ = cmd.Flags().GetBool("password-stdin")
+	loader.PasswordPrompt, _ = cmd.Flags().GetBool("password-prompt")
+	if loader.PasswordStdin && loader.PasswordPrompt {
+		return nil, fmt.Errorf("--password-stdin and --password-prompt are mutually exclusive")
+	}
+
+	return loader, nil
+}
+
+// openMonitor подключается к БД по имени профиля или строке подключения
+func openMonitor(cmd *cobra.Command, ref string) (database.DBMonitor, error) {
+	loader, err := profileLoader(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := loader.Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return connectWithOptions(cmd, profile)
+}
+
+// slowQueryOptions читает --slow-threshold, --slow-sort и --slow-limit; у команд без этих флагов - значения по умолчанию
+func slowQueryOptions(cmd *cobra.Command) (database.SlowQueryOptions, error) {
+	opts := database.DefaultSlowQueryOptions()
+	if threshold, err := cmd.Flags().GetDuration("slow-threshold"); err == nil {
+		opts.Threshold = threshold
+	}
+	if limit, err := cmd.Flags().GetInt("slow-limit"); err == nil {
+		opts.Limit = limit
+	}
+	if sortBy, err := cmd.Flags().GetString("slow-sort"); err == nil {
+		if sortBy != database.SlowQuerySortMean && sortBy != database.SlowQuerySortTotal {
+			return opts, fmt.Errorf("--slow-sort must be %s or %s, got %q", database.SlowQuerySortMean, database.SlowQuerySortTotal, sortBy)
+		}
+		opts.SortBy = sortBy
+	}
+	return opts, nil
+}
+
+// schemaOption читает --schema и --all-schemas; пустая строка - схема по умолчанию
+func schemaOption(cmd *cobra.Command) (string, error) {
+	schema, _ := cmd.Flags().GetString("schema")
+	allSchemas, _ := cmd.Flags().GetBool("all-schemas")
+	if allSchemas {
+		if schema != "" {
+			return "", fmt.Errorf("--schema and --all-schemas are mutually exclusive")
+		}
+		return database.AllSchemas, nil
+	}
+	return schema, nil
+}
+
+// applySchema выбирает схему в мониторе, проверяя, что СУБД поддерживает выбор и схема существует
+func applySchema(monitor database.DBMonitor, schema string) error {
+	if schema == "" {
+		return nil
+	}
+
+	schemas, err := monitor.ListSchemas()
+	if err != nil {
+		return err
+	}
+	if schema != database.AllSchemas && !slices.Contains(schemas, schema) {
+		return fmt.Errorf("schema %q not found, available: %s", schema, strings.Join(schemas, ", "))
+	}
+
+	monitor.SetSchema(schema)
+	return nil
+}
+
+// connectWithOptions подключается по профилю и передает монитору настройки медленных запросов и схему
+func connectWithOptions(cmd *cobra.Command, profile config.Profile) (database.DBMonitor, error) {
+	opts, err := slowQueryOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := schemaOption(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	monitor, err := connectProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	monitor.SetSlowQueryOptions(opts)
+	if err := applySchema(monitor, schema); err != nil {
+		monitor.Close()
+		return nil, err
+	}
+	return monitor, nil
+}
+
+// dockerArgs принимает профиль, "контейнер пользователь база", устаревшую форму с паролем
+// или ничего - тогда контейнер выбирается из найденных
+func dockerArgs(cmd *cobra.Command, args []string) error {
+	switch len(args) {
+	case 0, 1, 3, 4:
+		return nil
+	}
+	return fmt.Errorf("expected profile or container_name user database, got %d args", len(args))
+}
+
+// openDockerMonitor подключается к БД в контейнере по профилю или аргументам команды
+func openDockerMonitor(cmd *cobra.Command, args []string) (database.DBMonitor, error) {
+	loader, err := profileLoader(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Движок из --engine нужен до Complete, чтобы выбрать переменную с паролем
+	engine, _ := cmd.Flags().GetString("engine")
+
+	var profile config.Profile
+	switch len(args) {
+	case 0:
+		profile, err = pickDockerProfile(loader)
+	case 1:
+		profile, err = loader.Resolve(args[0])
+	case 3:
+		profile, err = loader.Complete(config.Profile{
+			Type: config.TypeDocker, Engine: engine, Container: args[0], User: args[1], Database: args[2],
+		})
+	default:
+		// Пароль в аргументах попадает в историю shell и ps; оставлено для совместимости
+		fmt.Fprintln(os.Stderr, "warning: password as an argument is deprecated, use a profile, --password-stdin or -W")
+		profile, err = loader.Complete(config.Profile{
+			Type: config.TypeDocker, Engine: engine, Container: args[0], User: args[1], Password: args[2], Database: args[3],
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if engine != "" {
+		profile.Engine = engine
+	}
+
+	return connectWithOptions(cmd, profile)
+}
+
+// pickDockerProfile показывает найденные контейнеры с БД и собирает профиль из окружения выбранного
+func pickDockerProfile(loader *config.Loader) (config.Profile, error) {
+	targets, err := database.DiscoverContainers()
+	if err != nil {
+		return config.Profile{}, err
+	}
+
+	target, err := database.PickContainer(targets)
+	if err != nil {
+		return config.Profile{}, err
+	}
+	if target == nil {
+		return config.Profile{}, fmt.Errorf("no container selected")
+	}
+
+	return loader.Complete(config.Profile{
+		Type:      config.TypeDocker,
+		Engine:    target.Engine,
+		Container: target.Name,
+		User:      target.User,
+		Password:  target.Password,
+		Database:  target.Database,
+	})
+}
+
+// connectProfile создает монитор под тип профиля и подключается
+func connectProfile(profile config.Profile) (database.DBMonitor, error) {
+	if profile.Type == config.TypeDocker {
+		monitor, err := database.NewDockerDBMonitorFromArgs(profile.Engine, profile.Container, profile.User, profile.Password, profile.Database, profile.Port)
+		if err != nil {
+			return nil, err
+		}
+		if err := monitor.Connect(""); err != nil {
+			return nil, fmt.Errorf("failed to connect to container: %w", err)
+		}
+		return monitor, nil
+	}
+
+	monitor, err := database.NewDBMonitor(profile.Type)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported database type: %w", err)
+	}
+	if err := monitor.Connect(profile.ConnectionString()); err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	return monitor, nil
+}
+
 // Команды для мониторинга БД
 var dbCmd = &cobra.Command{
 	Use:   "db",
@@ -61,24 +374,23 @@ var dbCmd = &cobra.Command{
 }
 
 var dbMonitorCmd = &cobra.Command{
-	Use:   "monitor [connection_string]",
+	Use:   "monitor [profile|connection_string]",
 	Short: "Database monitoring (TUI)",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		connectionString := args[0]
-
-		dbType := database.DetectDBType(connectionString)
-		monitor, err := database.NewDBMonitor(dbType)
+		monitor, err := openMonitor(cmd, args[0])
 		if err != nil {
-			return fmt.Errorf("unsupported database type: %w", err)
+			return err
 		}
+		defer monitor.Close()
 
-		if err := monitor.Connect(connectionString); err != nil {
-			return fmt.Errorf("failed to connect: %w", err)
+		engine, err := loadAlerts(cmd)
+		if err != nil {
+			return err
 		}
-		defer monitor.Close()
 
 		model := database.NewDBModel(monitor)
+		model.SetAlerts(engine)
 		p := tea.NewProgram(model)
 
 		_, err = p.Run()
@@ -87,94 +399,364 @@ var dbMonitorCmd = &cobra.Command{
 }
 
 var dbTablesCmd = &cobra.Command{
-	Use:   "tables [connection_string]",
+	Use:   "tables [profile|connection_string]",
 	Short: "Show database tables",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		connectionString := args[0]
+		monitor, err := openMonitor(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		defer monitor.Close()
+
+		tables, err := loadTables(cmd, monitor)
+		if err != nil {
+			return err
+		}
+
+		printTables(tables)
+
+		return nil
+	},
+}
+
+var dbSlowQueriesCmd = &cobra.Command{
+	Use:   "slow-queries [profile|connection_string]",
+	Short: "Show slow queries",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		monitor, err := openMonitor(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		defer monitor.Close()
+
+		slowQueries, err := monitor.GetSlowQueries()
+		if err != nil {
+			return fmt.Errorf("failed to get slow queries: %w", err)
+		}
+
+		printSlowQueries(slowQueries, monitor.SlowQueryOptions())
+		return nil
+	},
+}
+
+// loadTables получает таблицы и с --exact-count пересчитывает строки через COUNT(*)
+func loadTables(cmd *cobra.Command, monitor database.DBMonitor) ([]database.TableInfo, error) {
+	tables, err := monitor.GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tables: %w", err)
+	}
+
+	if exact, _ := cmd.Flags().GetBool("exact-count"); exact {
+		for i := range tables {
+			if tables[i].RowsExact {
+				continue
+			}
+			count, err := monitor.CountRows(tables[i])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", tables[i].QualifiedName(), err)
+			}
+			tables[i].Rows, tables[i].RowsExact = count, true
+		}
+	}
+	return tables, nil
+}
+
+// printTables выводит таблицы с размерами и, для PostgreSQL, состоянием vacuum
+func printTables(tables []database.TableInfo) {
+	fmt.Printf("Таблицы в базе данных:\n\n")
+	for _, table := range tables {
+		fmt.Printf("📋 %s\n", table.QualifiedName())
+		fmt.Printf("   Строк: %s | Размер: %s | Индексы: %d\n",
+			database.FormatRows(table), table.Size, table.Indexes)
+		if table.Sizes != nil {
+			fmt.Printf("   %s\n", table.Sizes.Parts())
+		}
+
+		if health := table.Health; health != nil {
+			fmt.Printf("   Живых: %d | Мертвых: %d (%.1f%%) | ", health.LiveTuples, health.DeadTuples, health.DeadPercent())
+			if health.BloatPercent >= 0 {
+				fmt.Printf("Раздувание: ~%.0f%%\n", health.BloatPercent)
+			} else {
+				fmt.Printf("Раздувание: n/a\n")
+			}
+			fmt.Printf("   Vacuum: %s | Autovacuum: %s | Analyze: %s | Autoanalyze: %s\n",
+				database.FormatSince(health.LastVacuum), database.FormatSince(health.LastAutovacuum),
+				database.FormatSince(health.LastAnalyze), database.FormatSince(health.LastAutoanalyze))
+			fmt.Printf("   Возраст XID: %d | До wraparound: %d\n", health.XIDAge, health.WraparoundRemaining())
+			if vacuum := health.Vacuum; vacuum != nil {
+				fmt.Printf("   🧹 VACUUM (pid %d): %s, %.1f%%\n", vacuum.PID, vacuum.Phase, vacuum.Percent())
+			}
+			for _, risk := range health.Risks() {
+				fmt.Printf("   ⚠️  %s\n", risk)
+			}
+		}
+		fmt.Println()
+	}
+}
+
+// printSlowQueries выводит медленные запросы со статистикой
+func printSlowQueries(slowQueries []database.SlowQuery, opts database.SlowQueryOptions) {
+	if len(slowQueries) == 0 {
+		fmt.Println("Медленных запросов не найдено")
+		return
+	}
+
+	fmt.Printf("Медленные запросы (порог %v, сортировка: %s):\n\n", opts.Threshold, opts.SortBy)
+	for i, query := range slowQueries {
+		fmt.Printf("%d. %s\n", i+1, query.Query)
+		for _, detail := range query.Details() {
+			fmt.Printf("   %s\n", detail)
+		}
+		fmt.Println()
+	}
+}
+
+var dbLocksCmd = &cobra.Command{
+	Use:   "locks [profile|connection_string]",
+	Short: "Show lock blocking chains",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		monitor, err := openMonitor(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		defer monitor.Close()
 
-		dbType := database.DetectDBType(connectionString)
-		monitor, err := database.NewDBMonitor(dbType)
+		locks, err := monitor.GetLocks()
 		if err != nil {
-			return fmt.Errorf("unsupported database type: %w", err)
+			return fmt.Errorf("failed to get locks: %w", err)
+		}
+
+		roots := database.BuildBlockingTree(locks)
+		if len(roots) == 0 {
+			fmt.Println("Блокировок не найдено")
+			return nil
 		}
 
-		if err := monitor.Connect(connectionString); err != nil {
-			return fmt.Errorf("failed to connect: %w", err)
+		fmt.Printf("Цепочки блокировок:\n\n")
+		database.WalkBlockingTree(roots, func(node *database.LockNode, depth int) {
+			lock := node.Lock
+			indent := strings.Repeat("   ", depth)
+			if depth == 0 {
+				fmt.Printf("🔒 %s (%s) блокирует: %d\n", lock.ID, lock.User, len(node.Children))
+			} else {
+				fmt.Printf("%s└─ %s (%s) ждет %s %s %v\n",
+					indent, lock.ID, lock.User, lock.Mode, lock.Relation, lock.WaitDuration)
+			}
+			if lock.Query != "" {
+				fmt.Printf("%s   %s\n", indent, lock.Query)
+			}
+		})
+
+		return nil
+	},
+}
+
+var dbReplicationCmd = &cobra.Command{
+	Use:   "replication [profile|connection_string]",
+	Short: "Show replication status, replica lag and replication slots",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		monitor, err := openMonitor(cmd, args[0])
+		if err != nil {
+			return err
 		}
 		defer monitor.Close()
 
-		tables, err := monitor.GetTables()
+		status, err := monitor.GetReplication()
 		if err != nil {
-			return fmt.Errorf("failed to get tables: %w", err)
+			return fmt.Errorf("failed to get replication status: %w", err)
 		}
 
-		fmt.Printf("Таблицы в базе данных:\n\n")
-		for _, table := range tables {
-			fmt.Printf("📋 %s\n", table.Name)
-			fmt.Printf("   Размер: %s | Индексы: %d\n\n",
-				table.Size, table.Indexes)
+		if status.Role == "" {
+			fmt.Println("Репликация не настроена")
+			return nil
+		}
+		fmt.Printf("Роль: %s\n\n", status.Role)
+
+		if upstream := status.Upstream; upstream != nil {
+			fmt.Printf("⬆️  Источник: %s\n", upstream.Source)
+			fmt.Printf("   IO: %s", upstream.IOState)
+			if upstream.SQLState != "" {
+				fmt.Printf(" | SQL: %s", upstream.SQLState)
+			}
+			fmt.Printf(" | Задержка: %s\n", database.FormatLag(upstream.Delay))
+			fmt.Printf("   Получено: %s | Применено: %s\n", upstream.ReceivePos, upstream.ReplayPos)
+			if upstream.LastError != "" {
+				fmt.Printf("   Ошибка: %s\n", upstream.LastError)
+			}
+			fmt.Println()
+		}
+
+		for _, replica := range status.Replicas {
+			fmt.Printf("⬇️  %s (%s) %s %s\n", replica.Name, replica.Client, replica.State, replica.SyncState)
+			fmt.Printf("   Отправка: %s | Write: %s | Flush: %s | Replay: %s | Отставание: %s\n\n",
+				database.FormatLagBytes(replica.SentLagBytes),
+				database.FormatLag(replica.WriteLag),
+				database.FormatLag(replica.FlushLag),
+				database.FormatLag(replica.ReplayLag),
+				database.FormatLagBytes(replica.ReplayLagBytes))
+		}
+
+		for _, slot := range status.Slots {
+			active := "активен"
+			if !slot.Active {
+				active = "неактивен"
+			}
+			fmt.Printf("🎰 %s (%s, %s) удерживает WAL: %s\n",
+				slot.Name, slot.Type, active, database.FormatSize(slot.RetainedBytes))
 		}
 
 		return nil
 	},
 }
 
-var dbSlowQueriesCmd = &cobra.Command{
-	Use:   "slow-queries [connection_string]",
-	Short: "Show slow queries",
+var dbIndexesCmd = &cobra.Command{
+	Use:   "indexes [profile|connection_string]",
+	Short: "Analyze indexes: unused, duplicate, invalid and missing",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		connectionString := args[0]
+		monitor, err := openMonitor(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		defer monitor.Close()
 
-		dbType := database.DetectDBType(connectionString)
-		monitor, err := database.NewDBMonitor(dbType)
+		report, err := monitor.GetIndexes()
 		if err != nil {
-			return fmt.Errorf("unsupported database type: %w", err)
+			return fmt.Errorf("failed to get indexes: %w", err)
+		}
+
+		fmt.Printf("Индексы в базе данных:\n\n")
+		for _, index := range report.Indexes {
+			scans := "n/a"
+			if index.Scans >= 0 {
+				scans = fmt.Sprintf("%d", index.Scans)
+			}
+			fmt.Printf("📇 %s.%s\n", index.Table, index.Name)
+			fmt.Printf("   Размер: %s | Сканов: %s\n", database.FormatSize(index.SizeBytes), scans)
+			fmt.Printf("   %s\n\n", index.Definition)
+		}
+
+		if len(report.Findings) == 0 {
+			fmt.Println("Проблем с индексами не найдено")
+			return nil
+		}
+
+		fmt.Printf("Проблемы (%d):\n\n", len(report.Findings))
+		for _, finding := range report.Findings {
+			target := finding.Table
+			if finding.Index != "" {
+				target = finding.Index + " на " + finding.Table
+			}
+			fmt.Printf("⚠️  [%s] %s - %s\n", finding.Kind, target, finding.Detail)
+			fmt.Printf("   %s\n\n", finding.Suggestion)
+		}
+
+		return nil
+	},
+}
+
+var dbExplainCmd = &cobra.Command{
+	Use:   "explain [profile|connection_string]",
+	Short: "Show the query plan (EXPLAIN FORMAT JSON) as a tree",
+	Long: `Show the query plan (EXPLAIN FORMAT JSON) as a tree with costs, row estimates
+and the most expensive node highlighted.
+
+With --analyze the query is executed inside a transaction that is rolled back.
+On MySQL/MariaDB only SELECT/VALUES/TABLE can be analyzed: changes to non-transactional
+tables (MyISAM) are not rolled back. MySQL needs 8.3+ for EXPLAIN ANALYZE in JSON.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query, _ := cmd.Flags().GetString("query")
+		analyze, _ := cmd.Flags().GetBool("analyze")
+		rawJSON, _ := cmd.Flags().GetBool("json")
+		if query == "" {
+			return fmt.Errorf("--query is required")
 		}
 
-		if err := monitor.Connect(connectionString); err != nil {
-			return fmt.Errorf("failed to connect: %w", err)
+		monitor, err := openMonitor(cmd, args[0])
+		if err != nil {
+			return err
 		}
 		defer monitor.Close()
 
-		slowQueries, err := monitor.GetSlowQueries()
+		plan, err := monitor.Explain(query, analyze)
 		if err != nil {
-			return fmt.Errorf("failed to get slow queries: %w", err)
+			return err
 		}
 
-		if len(slowQueries) == 0 {
-			fmt.Println("Медленных запросов не найдено")
+		if rawJSON {
+			fmt.Println(plan.Raw)
 			return nil
 		}
 
-		fmt.Printf("Медленные запросы:\n\n")
-		for i, query := range slowQueries {
-			fmt.Printf("%d. %s\n", i+1, query.Query)
-			fmt.Printf("   Время: %v | Пользователь: %s\n\n",
-				query.Duration, query.User)
+		fmt.Printf("Запрос:\n%s\n\n", plan.Query)
+		if plan.Generic {
+			fmt.Printf("Обобщенный план для запроса с параметрами\n\n")
+		}
+
+		expensive := plan.MostExpensive()
+		for _, row := range plan.Rows(nil) {
+			indent := strings.Repeat("   ", row.Depth)
+			marker := "->"
+			if row.Node == expensive {
+				marker = "🔥"
+			}
+			fmt.Printf("%s%s %s\n", indent, marker, row.Node.Summary())
+			for _, detail := range row.Node.Details {
+				fmt.Printf("%s      %s\n", indent, detail)
+			}
+		}
+
+		if plan.PlanningTime >= 0 {
+			fmt.Printf("\nПланирование: %v\n", plan.PlanningTime)
+		}
+		if plan.ExecutionTime >= 0 {
+			fmt.Printf("Выполнение: %v (изменения откачены)\n", plan.ExecutionTime)
 		}
 
 		return nil
 	},
 }
 
+func init() {
+	dbCmd.PersistentFlags().Duration("slow-threshold", database.DefaultSlowQueryThreshold, "Minimum mean execution time for slow queries (0 = all)")
+	dbCmd.PersistentFlags().String("slow-sort", database.SlowQuerySortMean, "Sort slow queries by mean or total time")
+	dbCmd.PersistentFlags().Int("slow-limit", database.DefaultSlowQueryLimit, "Number of slow queries to show")
+}
+
+// addSchemaFlags добавляет --schema и --all-schemas командам, показывающим таблицы
+func addSchemaFlags(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cmd.Flags().String("schema", "", "PostgreSQL schema or MySQL database to show tables from (default: public / current database)")
+		cmd.Flags().Bool("all-schemas", false, "Show tables from all user schemas (all databases for MySQL)")
+	}
+}
+
+func init() {
+	addSchemaFlags(dbMonitorCmd, dbTablesCmd)
+	dbTablesCmd.Flags().Bool("exact-count", false, "Count rows with COUNT(*) instead of planner estimates (slow on large tables)")
+}
+
+func init() {
+	dbExplainCmd.Flags().StringP("query", "q", "", "Query to explain")
+	dbExplainCmd.Flags().Bool("analyze", false, "Execute the query (EXPLAIN ANALYZE) inside a rolled back transaction")
+	dbExplainCmd.Flags().Bool("json", false, "Print the raw JSON plan")
+}
+
 var dbKillCmd = &cobra.Command{
-	Use:   "kill [connection_string] [connection_id]",
+	Use:   "kill [profile|connection_string] [connection_id]",
 	Short: "Kill database connection",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		connectionString := args[0]
 		connectionID := args[1]
 
-		dbType := database.DetectDBType(connectionString)
-		monitor, err := database.NewDBMonitor(dbType)
+		monitor, err := openMonitor(cmd, args[0])
 		if err != nil {
-			return fmt.Errorf("unsupported database type: %w", err)
-		}
-
-		if err := monitor.Connect(connectionString); err != nil {
-			return fmt.Errorf("failed to connect: %w", err)
+			return err
 		}
 		defer monitor.Close()
 
@@ -190,98 +772,114 @@ var dbKillCmd = &cobra.Command{
 // Docker команды для подключения к БД в контейнере
 var dbDockerCmd = &cobra.Command{
 	Use:   "docker",
-	Short: "Database monitoring via Docker exec",
+	Short: "Database monitoring via Docker exec (PostgreSQL, MySQL, MariaDB)",
+}
+
+var dbDockerDiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "List running database containers and credentials from their env",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, err := database.DiscoverContainers()
+		if err != nil {
+			return err
+		}
+
+		if len(targets) == 0 {
+			fmt.Println("Контейнеров с базами данных не найдено")
+			return nil
+		}
+
+		var data [][]string
+		for _, t := range targets {
+			password := "из env"
+			if t.Password == "" {
+				password = "нет"
+			}
+			data = append(data, []string{t.Name, t.Engine, t.Image, t.User, t.Database, password, t.Status})
+		}
+		fmt.Print(table.RenderTableWithHeader(
+			[]string{"container", "engine", "image", "user", "database", "password", "status"}, data))
+		fmt.Println("\nПодключение: uno db docker monitor (выбор из списка) или uno db docker monitor <container> <user> <database>")
+
+		return nil
+	},
+}
+
+func init() {
+	addSchemaFlags(dbDockerMonitorCmd, dbDockerTablesCmd)
+	dbDockerTablesCmd.Flags().Bool("exact-count", false, "Count rows with COUNT(*) instead of planner estimates (slow on large tables)")
+	dbDockerCmd.PersistentFlags().String("engine", "", "Database engine in the container: postgresql, mysql or mariadb (default: detect from image)")
 }
 
 var dbDockerMonitorCmd = &cobra.Command{
-	Use:   "monitor [container_name] [user] [password] [database]",
+	Use:   "monitor [profile | container_name user database]",
 	Short: "Database monitoring via Docker exec (TUI)",
-	Args:  cobra.ExactArgs(4),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		containerName := args[0]
-		user := args[1]
-		password := args[2]
-		dbName := args[3]
+	Long: `Database monitoring via Docker exec (TUI).
 
-		monitor := database.NewDockerDBMonitorFromArgs(containerName, user, password, dbName, "5432")
+Without arguments, running PostgreSQL/MySQL/MariaDB containers are listed and credentials
+are taken from the chosen container's environment.`,
+	Args: dockerArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		monitor, err := openDockerMonitor(cmd, args)
+		if err != nil {
+			return err
+		}
+		defer monitor.Close()
 
-		if err := monitor.Connect(""); err != nil {
-			return fmt.Errorf("failed to connect to container: %w", err)
+		engine, err := loadAlerts(cmd)
+		if err != nil {
+			return err
 		}
 
 		model := database.NewDBModel(monitor)
+		model.SetAlerts(engine)
 		p := tea.NewProgram(model)
 
-		_, err := p.Run()
+		_, err = p.Run()
 		return err
 	},
 }
 
 var dbDockerTablesCmd = &cobra.Command{
-	Use:   "tables [container_name] [user] [password] [database]",
+	Use:   "tables [profile | container_name user database]",
 	Short: "Show database tables via Docker exec",
-	Args:  cobra.ExactArgs(4),
+	Args:  dockerArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		containerName := args[0]
-		user := args[1]
-		password := args[2]
-		dbName := args[3]
-
-		monitor := database.NewDockerDBMonitorFromArgs(containerName, user, password, dbName, "5432")
-
-		if err := monitor.Connect(""); err != nil {
-			return fmt.Errorf("failed to connect to container: %w", err)
+		monitor, err := openDockerMonitor(cmd, args)
+		if err != nil {
+			return err
 		}
+		defer monitor.Close()
 
-		tables, err := monitor.GetTables()
+		tables, err := loadTables(cmd, monitor)
 		if err != nil {
-			return fmt.Errorf("failed to get tables: %w", err)
+			return err
 		}
 
-		fmt.Printf("Таблицы в базе данных:\n\n")
-		for _, table := range tables {
-			fmt.Printf("📋 %s\n", table.Name)
-			fmt.Printf("   Размер: %s | Индексы: %d\n\n",
-				table.Size, table.Indexes)
-		}
+		printTables(tables)
 
 		return nil
 	},
 }
 
 var dbDockerSlowQueriesCmd = &cobra.Command{
-	Use:   "slow-queries [container_name] [user] [password] [database]",
+	Use:   "slow-queries [profile | container_name user database]",
 	Short: "Show slow queries via Docker exec",
-	Args:  cobra.ExactArgs(4),
+	Args:  dockerArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		containerName := args[0]
-		user := args[1]
-		password := args[2]
-		dbName := args[3]
-
-		monitor := database.NewDockerDBMonitorFromArgs(containerName, user, password, dbName, "5432")
-
-		if err := monitor.Connect(""); err != nil {
-			return fmt.Errorf("failed to connect to container: %w", err)
+		monitor, err := openDockerMonitor(cmd, args)
+		if err != nil {
+			return err
 		}
+		defer monitor.Close()
 
 		slowQueries, err := monitor.GetSlowQueries()
 		if err != nil {
 			return fmt.Errorf("failed to get slow queries: %w", err)
 		}
 
-		if len(slowQueries) == 0 {
-			fmt.Println("Медленных запросов не найдено")
-			return nil
-		}
-
-		fmt.Printf("Медленные запросы:\n\n")
-		for i, query := range slowQueries {
-			fmt.Printf("%d. %s\n", i+1, query.Query)
-			fmt.Printf("   Время: %v | Пользователь: %s\n\n",
-				query.Duration, query.User)
-		}
-
+		printSlowQueries(slowQueries, monitor.SlowQueryOptions())
 		return nil
 	},
 }
@@ -290,15 +888,21 @@ func Execute() {
 	rootCmd.AddCommand(monitorCmd)
 	rootCmd.AddCommand(traceCmd)
 	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(serveCmd)
 
 	// Добавляем команды для мониторинга БД
 	dbCmd.AddCommand(dbMonitorCmd)
 	dbCmd.AddCommand(dbTablesCmd)
 	dbCmd.AddCommand(dbSlowQueriesCmd)
+	dbCmd.AddCommand(dbLocksCmd)
+	dbCmd.AddCommand(dbReplicationCmd)
+	dbCmd.AddCommand(dbIndexesCmd)
+	dbCmd.AddCommand(dbExplainCmd)
 	dbCmd.AddCommand(dbKillCmd)
 
 	// Добавляем Docker команды
 	dbDockerCmd.AddCommand(dbDockerMonitorCmd)
+	dbDockerCmd.AddCommand(dbDockerDiscoverCmd)
 	dbDockerCmd.AddCommand(dbDockerTablesCmd)
 	dbDockerCmd.AddCommand(dbDockerSlowQueriesCmd)
 	dbCmd.AddCommand(dbDockerCmd)
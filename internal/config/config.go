@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config файл настроек uno
+//
+//	profiles:
+//	  prod-pg:
+//	    dsn: postgres://monitor@db.prod:5432/app?sslmode=require
+//	    password_env: PROD_PG_PASSWORD
+//	  dev-docker:
+//	    type: docker
+//	    container: dp-postgres-dev
+//	    user: dp-api
+//	    database: dp
+type Config struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Dir каталог настроек uno: $XDG_CONFIG_HOME/uno или ~/.config/uno
+func Dir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "uno")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "uno")
+}
+
+// DefaultPath путь к config.yaml; переопределяется переменной UNO_CONFIG
+func DefaultPath() string {
+	if path := os.Getenv("UNO_CONFIG"); path != "" {
+		return path
+	}
+	dir := Dir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+// Load читает конфиг. Пустой path означает файл по умолчанию; если его нет, возвращается пустой конфиг
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+	}
+
+	cfg := &Config{Profiles: map[string]Profile{}}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Типы профилей подключения
+const (
+	TypePostgreSQL = "postgresql"
+	TypeMySQL      = "mysql"
+	TypeDocker     = "docker"
+	TypeSQLite     = "sqlite"
+	TypeRedis      = "redis"
+	TypeMongoDB    = "mongodb"
+)
+
+// Profile именованное подключение к БД. Строковые поля поддерживают ${VAR} из окружения
+type Profile struct {
+	Name        string `yaml:"-"`
+	Type        string `yaml:"type"` // postgresql, mysql, sqlite, redis, mongodb или docker; для dsn определяется по схеме
+	DSN         string `yaml:"dsn"`
+	Host        string `yaml:"host"`
+	Port        string `yaml:"port"` // для docker - порт сервера внутри контейнера; по умолчанию из его окружения
+	User        string `yaml:"user"`
+	Password    string `yaml:"password"`
+	PasswordEnv string `yaml:"password_env"` // переменная окружения с паролем
+	Database    string `yaml:"database"`     // для sqlite - путь к файлу
+	SSLMode     string `yaml:"sslmode"`
+	OptionFile  string `yaml:"option_file"` // MySQL option file вместо ~/.my.cnf
+	Container   string `yaml:"container"`   // имя контейнера для type: docker
+	Engine      string `yaml:"engine"`      // СУБД в контейнере: postgresql, mysql или mariadb; по умолчанию определяется
+
+	scheme         string     // схема dsn, например rediss или mongodb+srv
+	params         url.Values // параметры из dsn, которые не разобраны в поля
+	optionPassword string     // пароль из MySQL option files
+}
+
+// Loader превращает имя профиля или строку подключения в готовый профиль с паролем.
+// Пароль ищется по порядку: профиль, password_env, --password-stdin, запрос в терминале,
+// PGPASSWORD/MYSQL_PWD/REDISCLI_AUTH (для docker - по движку), .pgpass (PGPASSFILE) или MySQL option files
+type Loader struct {
+	Config *Config
+	// PasswordStdin читать пароль из первой строки stdin
+	PasswordStdin bool
+	// PasswordPrompt запрашивать пароль в терминале
+	PasswordPrompt bool
+
+	Stdin  io.Reader
+	Stderr io.Writer
+
+	stdinPassword *string // пароль из stdin читается один раз на процесс
+}
+
+// NewLoader создает загрузчик профилей поверх конфига
+func NewLoader(cfg *Config) *Loader {
+	return &Loader{Config: cfg, Stdin: os.Stdin, Stderr: os.Stderr}
+}
+
+// Resolve находит профиль по имени; если такого нет, ref считается строкой подключения
+func (l *Loader) Resolve(ref string) (Profile, error) {
+	profile, ok := l.Config.Profiles[ref]
+	if ok {
+		profile.Name = ref
+	} else {
+		if !strings.Contains(ref, "://") && !strings.HasPrefix(ref, "file:") {
+			return Profile{}, fmt.Errorf("unknown profile %q (expected profile name or connection string)", ref)
+		}
+		profile = Profile{DSN: ref}
+	}
+
+	return l.Complete(profile)
+}
+
+// Complete раскрывает переменные окружения, разбирает dsn и подставляет пароль
+func (l *Loader) Complete(p Profile) (Profile, error) {
+	for _, field := range []*string{&p.Type, &p.DSN, &p.Host, &p.Port, &p.User, &p.Password,
+		&p.PasswordEnv, &p.Database, &p.SSLMode, &p.OptionFile, &p.Container, &p.Engine} {
+		*field = os.ExpandEnv(*field)
+	}
+
+	if p.DSN != "" {
+		if err := p.parseDSN(); err != nil {
+			return Profile{}, err
+		}
+	}
+
+	switch p.Type {
+	case TypePostgreSQL, TypeMySQL, TypeDocker, TypeSQLite, TypeRedis, TypeMongoDB:
+	case "":
+		return Profile{}, fmt.Errorf("profile %q: type is required without dsn", p.Name)
+	default:
+		return Profile{}, fmt.Errorf("profile %q: unsupported type %q", p.Name, p.Type)
+	}
+	if p.Type == TypeDocker && p.Container == "" {
+		return Profile{}, fmt.Errorf("profile %q: container is required for docker", p.Name)
+	}
+
+	if p.Type == TypeSQLite {
+		// Файловой БД пароль не нужен
+		if p.DSN == "" && p.Database == "" {
+			return Profile{}, fmt.Errorf("profile %q: dsn or database is required for sqlite", p.Name)
+		}
+		return p, nil
+	}
+
+	if p.Type == TypeMySQL {
+		if err := p.applyOptionFiles(); err != nil {
+			return Profile{}, err
+		}
+	}
+
+	if p.Password == "" {
+		password, err := l.password(p)
+		if err != nil {
+			return Profile{}, err
+		}
+		p.Password = password
+	}
+
+	return p, nil
+}
+
+// password ищет пароль для профиля, в котором он не задан явно
+func (l *Loader) password(p Profile) (string, error) {
+	if p.PasswordEnv != "" {
+		if password, ok := os.LookupEnv(p.PasswordEnv); ok {
+			return password, nil
+		}
+		return "", fmt.Errorf("profile %q: environment variable %s is not set", p.Name, p.PasswordEnv)
+	}
+
+	if l.PasswordStdin {
+		return l.readStdinPassword()
+	}
+	if l.PasswordPrompt {
+		return l.promptPassword(p)
+	}
+
+	switch p.Type {
+	case TypePostgreSQL:
+		if password := os.Getenv("PGPASSWORD"); password != "" {
+			return password, nil
+		}
+		return lookupPgpass(p)
+	case TypeDocker:
+		// Без явного движка подходит переменная любого клиента
+		if p.Engine != "mysql" && p.Engine != "mariadb" {
+			if password := os.Getenv("PGPASSWORD"); password != "" {
+				return password, nil
+			}
+		}
+		if p.Engine == "" || p.Engine == "mysql" || p.Engine == "mariadb" {
+			return os.Getenv("MYSQL_PWD"), nil
+		}
+	case TypeMySQL:
+		if password := os.Getenv("MYSQL_PWD"); password != "" {
+			return password, nil
+		}
+		return p.optionPassword, nil
+	case TypeRedis:
+		return os.Getenv("REDISCLI_AUTH"), nil
+	}
+
+	return "", nil
+}
+
+// parseDSN раскладывает URL подключения по полям профиля
+func (p *Profile) parseDSN() error {
+	u, err := url.Parse(p.DSN)
+	if err != nil {
+		return fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	p.scheme = u.Scheme
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		if p.Type == "" {
+			p.Type = TypePostgreSQL
+		}
+	case "mysql":
+		if p.Type == "" {
+			p.Type = TypeMySQL
+		}
+	case "sqlite", "file":
+		// Путь к файлу разбирает сам монитор SQLite, dsn передается как есть
+		if p.Type == "" {
+			p.Type = TypeSQLite
+		}
+		return nil
+	case "redis", "rediss":
+		if p.Type == "" {
+			p.Type = TypeRedis
+		}
+	case "mongodb", "mongodb+srv":
+		if p.Type == "" {
+			p.Type = TypeMongoDB
+		}
+	default:
+		return fmt.Errorf("unsupported connection string scheme: %q", u.Scheme)
+	}
+
+	if u.User != nil {
+		p.User = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			p.Password = password
+		}
+	}
+	if host, port, err := net.SplitHostPort(u.Host); err == nil {
+		p.Host, p.Port = host, port
+	} else if u.Host != "" {
+		p.Host = u.Host
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		p.Database = db
+	}
+
+	query := u.Query()
+	if sslmode := query.Get("sslmode"); sslmode != "" {
+		p.SSLMode = sslmode
+		query.Del("sslmode")
+	}
+	p.params = query
+
+	return nil
+}
+
+// ConnectionString строка подключения для database.DBMonitor.Connect
+func (p Profile) ConnectionString() string {
+	if p.Type == TypeSQLite {
+		if p.DSN != "" {
+			return p.DSN
+		}
+		return "sqlite://" + p.Database
+	}
+
+	u := url.URL{Path: "/" + p.Database}
+	query := url.Values{}
+	for k, v := range p.params {
+		query[k] = v
+	}
+
+	host := p.Host
+	switch p.Type {
+	case TypeMySQL:
+		u.Scheme = "mysql"
+		if host == "" {
+			host = "127.0.0.1"
+		}
+	case TypeRedis:
+		u.Scheme = "redis"
+		if p.scheme == "rediss" {
+			u.Scheme = p.scheme
+		}
+		if host == "" {
+			host = "localhost"
+		}
+	case TypeMongoDB:
+		u.Scheme = "mongodb"
+		if p.scheme == "mongodb+srv" {
+			u.Scheme = p.scheme
+		}
+		if host == "" {
+			host = "localhost"
+		}
+		if p.Database == "" {
+			u.Path = "/"
+		}
+	default:
+		u.Scheme = "postgres"
+		if host == "" {
+			host = "localhost"
+		}
+		if p.SSLMode != "" {
+			query.Set("sslmode", p.SSLMode)
+		}
+	}
+
+	u.Host = host
+	if p.Port != "" {
+		u.Host = net.JoinHostPort(host, p.Port)
+	}
+	if p.Password != "" {
+		u.User = url.UserPassword(p.User, p.Password)
+	} else if p.User != "" {
+		u.User = url.User(p.User)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// readStdinPassword читает пароль из первой строки stdin
+func (l *Loader) readStdinPassword() (string, error) {
+	if l.stdinPassword != nil {
+		return *l.stdinPassword, nil
+	}
+
+	line, err := bufio.NewReader(l.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	l.stdinPassword = &password
+
+	return password, nil
+}
+
+// promptPassword запрашивает пароль в терминале без эха
+func (l *Loader) promptPassword(p Profile) (string, error) {
+	f, ok := l.Stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return "", fmt.Errorf("cannot prompt for password: stdin is not a terminal (use --password-stdin)")
+	}
+
+	target := p.Name
+	if target == "" {
+		target = fmt.Sprintf("%s@%s", p.User, p.Host)
+		if p.Type == TypeDocker {
+			target = fmt.Sprintf("%s@%s", p.User, p.Container)
+		}
+	}
+	fmt.Fprintf(l.Stderr, "Password for %s: ", target)
+
+	password, err := term.ReadPassword(int(f.Fd()))
+	fmt.Fprintln(l.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	return string(password), nil
+}
+
+// lookupPgpass ищет пароль в PGPASSFILE или ~/.pgpass по правилам libpq:
+// строки host:port:database:user:password, * совпадает с чем угодно, \ экранирует : и \
+func lookupPgpass(p Profile) (string, error) {
+	path := os.Getenv("PGPASSFILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		path = filepath.Join(home, ".pgpass")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil
+	}
+	// libpq игнорирует файл, доступный группе или остальным
+	if info.Mode().Perm()&0o077 != 0 {
+		return "", nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open password file: %w", err)
+	}
+	defer f.Close()
+
+	host := p.Host
+	if host == "" {
+		host = "localhost"
+	}
+	port := p.Port
+	if port == "" {
+		port = "5432"
+	}
+	want := []string{host, port, p.Database, p.User}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := splitPgpassLine(line)
+		if len(fields) != 5 {
+			continue
+		}
+
+		matched := true
+		for i, value := range want {
+			if fields[i] != "*" && fields[i] != value {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return fields[4], nil
+		}
+	}
+
+	return "", scanner.Err()
+}
+
+func splitPgpassLine(line string) []string {
+	var fields []string
+	var current strings.Builder
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':' && len(fields) < 4:
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	return append(fields, current.String())
+}
+
+// applyOptionFiles дополняет MySQL профиль из секций [client] и [mysql] option files.
+// Явно заданные поля профиля не перезаписываются; пароль используется последним, после stdin и MYSQL_PWD
+func (p *Profile) applyOptionFiles() error {
+	var paths []string
+	if p.OptionFile != "" {
+		paths = []string{p.OptionFile}
+	} else {
+		paths = []string{"/etc/my.cnf", "/etc/mysql/my.cnf"}
+		if home, err := os.UserHomeDir(); err == nil {
+			paths = append(paths, filepath.Join(home, ".my.cnf"))
+		}
+	}
+
+	options := map[string]string{}
+	for _, path := range paths {
+		if err := readOptionFile(path, options); err != nil {
+			if p.OptionFile == "" && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+	}
+
+	fill := func(field *string, key string) {
+		if *field == "" {
+			*field = options[key]
+		}
+	}
+	fill(&p.Host, "host")
+	fill(&p.Port, "port")
+	fill(&p.User, "user")
+	p.optionPassword = options["password"]
+	fill(&p.Database, "database")
+
+	return nil
+}
+
+// readOptionFile читает key=value из секций [client] и [mysql]; более поздние файлы перекрывают ранние
+func readOptionFile(path string, options map[string]string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open option file: %w", err)
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != "client" && section != "mysql" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		key = strings.ReplaceAll(strings.TrimSpace(key), "-", "_")
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		options[key] = value
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,50 @@
+package database
+
+import "time"
+
+// counterSample снимок накопительных счетчиков сервера
+type counterSample struct {
+	at       time.Time
+	queries  float64       // выполненные запросы/транзакции
+	errors   float64       // откаты и ошибки
+	busyTime time.Duration // суммарное время выполнения
+}
+
+// counterTracker вычисляет скорости по разнице счетчиков между тиками
+type counterTracker struct {
+	first *counterSample
+	prev  *counterSample
+}
+
+// update заполняет QueriesPerSecond, AvgResponseTime и ErrorCount по новому снимку.
+// ErrorCount считается с начала мониторинга, скорости - за последний интервал.
+func (c *counterTracker) update(sample counterSample, stats *DBStats) {
+	// Счетчики уменьшились - сервер перезапущен или статистика сброшена
+	if c.prev != nil && (sample.queries < c.prev.queries || sample.errors < c.prev.errors) {
+		c.first = nil
+		c.prev = nil
+	}
+
+	if c.first == nil {
+		first := sample
+		c.first = &first
+	}
+
+	if c.prev != nil {
+		elapsed := sample.at.Sub(c.prev.at).Seconds()
+		queries := sample.queries - c.prev.queries
+
+		if elapsed > 0 {
+			stats.QueriesPerSecond = queries / elapsed
+		}
+		if queries > 0 {
+			busy := sample.busyTime - c.prev.busyTime
+			stats.AvgResponseTime = time.Duration(float64(busy) / queries)
+		}
+	}
+
+	stats.ErrorCount = int(sample.errors - c.first.errors)
+
+	prev := sample
+	c.prev = &prev
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -10,30 +11,100 @@ import (
 type DBStats struct {
 	ActiveConnections int
 	MaxConnections    int
-	QueriesPerSecond  float64
-	AvgResponseTime   time.Duration
+	QueriesPerSecond  float64       // транзакции/запросы в секунду за последний интервал
+	AvgResponseTime   time.Duration // среднее время выполнения за последний интервал
 	DatabaseSize      string
 	TableCount        int
 	Tables            []TableInfo
 	SlowQueries       []SlowQuery
-	ErrorCount        int
+	ErrorCount        int          // откаты и ошибки с начала мониторинга
+	Properties        []DBProperty // показатели, специфичные для СУБД
 	LastUpdate        time.Time
 }
 
+// DBProperty именованный показатель СУБД для обзора, например настройка PRAGMA SQLite
+type DBProperty struct {
+	Name  string
+	Value string
+}
+
+// Metrics плоский набор метрик статистики для правил алертинга
+func (s *DBStats) Metrics() map[string]float64 {
+	metrics := map[string]float64{
+		"db.active_connections": float64(s.ActiveConnections),
+		"db.max_connections":    float64(s.MaxConnections),
+		"db.qps":                s.QueriesPerSecond,
+		"db.avg_response_ms":    float64(s.AvgResponseTime) / float64(time.Millisecond),
+		"db.error_count":        float64(s.ErrorCount),
+		"db.slow_queries":       float64(len(s.SlowQueries)),
+		"db.table_count":        float64(s.TableCount),
+	}
+	if bytes, ok := ParseSize(s.DatabaseSize); ok {
+		metrics["db.size_bytes"] = bytes
+	}
+	return metrics
+}
+
 // TableInfo содержит информацию о таблице
 type TableInfo struct {
+	Schema     string // схема PostgreSQL, база MySQL или MongoDB; пусто для остальных СУБД
 	Name       string
 	Size       string
+	Sizes      *TableSizes // размер по частям; nil, если неизвестен
+	Rows       int64       // -1, если количество строк неизвестно
+	RowsExact  bool        // Rows посчитано COUNT(*), а не взято из статистики
 	Indexes    int
 	LastUpdate time.Time
+	Health     *TableHealth // vacuum и раздувание, только для PostgreSQL
+	Growth     *TableGrowth // заполняет GrowthTracker; nil, пока снимков недостаточно
 }
 
 // SlowQuery содержит информацию о медленном запросе
 type SlowQuery struct {
-	Query     string
-	Duration  time.Duration
+	QueryID   string        // queryid в pg_stat_statements, digest в MySQL
+	Query     string        // полный нормализованный текст
+	Sample    string        // пример с конкретными значениями, если СУБД его хранит (MySQL 8.0+)
+	Duration  time.Duration // среднее время для агрегированной статистики, иначе время выполнения
 	Timestamp time.Time
 	User      string
+	Schema    string // база, в которой выполнялся запрос (MySQL); в digest нет пользователя
+
+	// Агрегированная статистика pg_stat_statements и performance_schema; для журналов
+	// отдельных запросов (Redis SLOWLOG, MongoDB) Calls равен 0
+	Calls           int64
+	TotalTime       time.Duration
+	MaxTime         time.Duration
+	StddevTime      time.Duration // -1, если неизвестно (MySQL)
+	Rows            int64
+	SharedBlksHit   int64
+	SharedBlksRead  int64
+	TempBlksRead    int64
+	TempBlksWritten int64
+	TempDiskTables  int64 // временные таблицы на диске (MySQL)
+}
+
+// ConnectionInfo содержит информацию о соединении (бэкенде) с БД
+type ConnectionInfo struct {
+	ID         string
+	User       string
+	ClientAddr string
+	State      string
+	Query      string
+	QueryAge   time.Duration
+	WaitEvent  string
+}
+
+// LockInfo содержит информацию о сессии, участвующей в цепочке блокировок
+type LockInfo struct {
+	ID           string
+	BlockedBy    []string
+	User         string
+	State        string
+	LockType     string
+	Mode         string
+	Relation     string
+	WaitDuration time.Duration
+	Query        string
 }
 
 // DBMonitor интерфейс для мониторинга разных типов БД
@@ -41,19 +112,39 @@ type DBMonitor interface {
 	Connect(connectionString string) error
 	GetStats() (*DBStats, error)
 	GetTables() ([]TableInfo, error)
+	CountRows(table TableInfo) (int64, error)
 	GetSlowQueries() ([]SlowQuery, error)
+	SetSlowQueryOptions(opts SlowQueryOptions)
+	SlowQueryOptions() SlowQueryOptions
+	ResetSlowQueries() error
+	SetSchema(schema string)
+	Schema() string
+	ListSchemas() ([]string, error)
+	GetConnections() ([]ConnectionInfo, error)
+	GetLocks() ([]LockInfo, error)
+	GetReplication() (*ReplicationStatus, error)
+	GetIndexes() (*IndexReport, error)
+	Explain(query string, analyze bool) (*ExplainPlan, error)
 	KillConnection(connectionID string) error
 	Close() error
 }
 
 // PostgreSQLMonitor реализация для PostgreSQL
 type PostgreSQLMonitor struct {
-	db *sql.DB
+	db       *sql.DB
+	counters counterTracker
+
+	slowQuerySettings
+	schemaSettings
 }
 
 // MySQLMonitor реализация для MySQL
 type MySQLMonitor struct {
-	db *sql.DB
+	db       *sql.DB
+	counters counterTracker
+
+	slowQuerySettings
+	schemaSettings
 }
 
 // NewPostgreSQLMonitor создает новый монитор для PostgreSQL
@@ -68,17 +159,17 @@ func NewMySQLMonitor() *MySQLMonitor {
 
 // DetectDBType определяет тип БД по connection string
 func DetectDBType(connectionString string) string {
-	if len(connectionString) > 0 {
-		switch connectionString[0] {
-		case 'p':
-			if len(connectionString) > 8 && connectionString[:8] == "postgres" {
-				return "postgresql"
-			}
-		case 'm':
-			if len(connectionString) > 5 && connectionString[:5] == "mysql" {
-				return "mysql"
-			}
-		}
+	switch {
+	case strings.HasPrefix(connectionString, "postgres"):
+		return "postgresql"
+	case strings.HasPrefix(connectionString, "mysql"):
+		return "mysql"
+	case strings.HasPrefix(connectionString, "sqlite://"), strings.HasPrefix(connectionString, "file:"):
+		return "sqlite"
+	case strings.HasPrefix(connectionString, "redis://"), strings.HasPrefix(connectionString, "rediss://"):
+		return "redis"
+	case strings.HasPrefix(connectionString, "mongodb://"), strings.HasPrefix(connectionString, "mongodb+srv://"):
+		return "mongodb"
 	}
 	return "unknown"
 }
@@ -90,12 +181,91 @@ func NewDBMonitor(dbType string) (DBMonitor, error) {
 		return NewPostgreSQLMonitor(), nil
 	case "mysql":
 		return NewMySQLMonitor(), nil
+	case "sqlite":
+		return NewSQLiteMonitor(), nil
+	case "redis":
+		return NewRedisMonitor(), nil
+	case "mongodb":
+		return NewMongoDBMonitor(), nil
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", dbType)
 	}
 }
 
-// NewDockerDBMonitorFromArgs создает Docker монитор из аргументов
-func NewDockerDBMonitorFromArgs(containerName, user, password, database, port string) DBMonitor {
-	return NewDockerDBMonitor(containerName, user, password, database, port)
+// NewDockerDBMonitorFromArgs создает Docker монитор под движок БД в контейнере.
+// Пустой engine означает автоопределение по образу и окружению контейнера, пустой port - порт
+// из окружения контейнера
+func NewDockerDBMonitorFromArgs(engine, containerName, user, password, database, port string) (DBMonitor, error) {
+	if engine == "" {
+		detected, err := DetectDockerEngine(containerName)
+		if err != nil {
+			return nil, err
+		}
+		engine = detected
+	}
+
+	switch engine {
+	case "postgresql", "postgres":
+		return NewDockerDBMonitor(containerName, user, password, database, port), nil
+	case "mysql":
+		return NewDockerMySQLMonitor(containerName, "mysql", user, password, database, port), nil
+	case "mariadb":
+		return NewDockerMySQLMonitor(containerName, "mariadb", user, password, database, port), nil
+	default:
+		return nil, fmt.Errorf("unsupported docker engine: %s", engine)
+	}
+}
+
+// splitIDs разбирает список идентификаторов, разделенных запятыми
+func splitIDs(list string) []string {
+	var ids []string
+	for _, id := range strings.Split(list, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ParseSize переводит размер вида "8192 bytes", "16 kB", "12.34 MB" в байты
+func ParseSize(size string) (float64, bool) {
+	fields := strings.Fields(size)
+	if len(fields) == 0 {
+		return 0, false
+	}
+
+	var value float64
+	if _, err := fmt.Sscanf(fields[0], "%g", &value); err != nil {
+		return 0, false
+	}
+	if len(fields) == 1 {
+		return value, true
+	}
+
+	multipliers := map[string]float64{
+		"bytes": 1, "b": 1,
+		"kb": 1 << 10, "mb": 1 << 20, "gb": 1 << 30, "tb": 1 << 40, "pb": 1 << 50,
+	}
+	multiplier, ok := multipliers[strings.ToLower(fields[1])]
+	if !ok {
+		return 0, false
+	}
+
+	return value * multiplier, true
+}
+
+// FormatSize форматирует размер в байтах так, чтобы его разбирал ParseSize
+func FormatSize(b int64) string {
+	units := []string{"bytes", "kB", "MB", "GB", "TB"}
+	v := float64(b)
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d bytes", b)
+	}
+	return fmt.Sprintf("%.2f %s", v, units[i])
 }
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// DockerTarget контейнер с БД и учетные данные из его окружения
+type DockerTarget struct {
+	ContainerID string
+	Name        string
+	Image       string
+	Status      string
+	Engine      string // postgresql, mysql или mariadb
+	User        string
+	Password    string
+	Database    string
+}
+
+// DiscoverContainers находит запущенные контейнеры с PostgreSQL, MySQL или MariaDB
+// и читает пользователя, пароль и базу из переменных окружения официальных и bitnami образов
+func DiscoverContainers() ([]DockerTarget, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerExecTimeout)
+	defer cancel()
+
+	containers, err := cli.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var targets []DockerTarget
+	for _, c := range containers {
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil || info.Config == nil {
+			continue
+		}
+
+		env := make(map[string]string)
+		for _, kv := range info.Config.Env {
+			name, value, _ := strings.Cut(kv, "=")
+			env[name] = value
+		}
+
+		engine := engineFromImage(c.Image)
+		if engine == "" {
+			engine = engineFromEnv(info.Config.Env)
+		}
+		if engine == "" {
+			continue
+		}
+
+		target := DockerTarget{
+			ContainerID: c.ID,
+			Name:        strings.TrimPrefix(info.Name, "/"),
+			Image:       c.Image,
+			Status:      c.Status,
+			Engine:      engine,
+		}
+		target.User, target.Password, target.Database = credentialsFromEnv(engine, env)
+		targets = append(targets, target)
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+
+	return targets, nil
+}
+
+// credentialsFromEnv достает учетные данные из окружения контейнера с учетом значений по умолчанию образов
+func credentialsFromEnv(engine string, env map[string]string) (user, password, database string) {
+	first := func(names ...string) string {
+		for _, name := range names {
+			if value := env[name]; value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	if engine == "postgresql" {
+		user = first("POSTGRES_USER", "POSTGRESQL_USERNAME")
+		if user == "" {
+			user = "postgres"
+		}
+		password = first("POSTGRES_PASSWORD", "POSTGRESQL_PASSWORD")
+		database = first("POSTGRES_DB", "POSTGRESQL_DATABASE")
+		if database == "" {
+			database = user
+		}
+		return user, password, database
+	}
+
+	// MySQL/MariaDB: обычный пользователь, если он создан образом, иначе root
+	user = first("MARIADB_USER", "MYSQL_USER")
+	password = first("MARIADB_PASSWORD", "MYSQL_PASSWORD")
+	if user == "" {
+		user = "root"
+		password = first("MARIADB_ROOT_PASSWORD", "MYSQL_ROOT_PASSWORD")
+	}
+	database = first("MARIADB_DATABASE", "MYSQL_DATABASE")
+
+	return user, password, database
+}
+
+// engineFromEnv определяет СУБД по переменным окружения официальных образов
+func engineFromEnv(env []string) string {
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		switch {
+		case strings.HasPrefix(name, "MARIADB_"):
+			return "mariadb"
+		case strings.HasPrefix(name, "MYSQL_"):
+			return "mysql"
+		case strings.HasPrefix(name, "POSTGRES_"), strings.HasPrefix(name, "PG"):
+			return "postgresql"
+		}
+	}
+	return ""
+}
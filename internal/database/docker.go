@@ -1,49 +1,46 @@
 package database
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"os/exec"
+	"io"
 	"strconv"
 	"strings"
 	"time"
-)
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
 
-// DockerDBMonitor мониторинг БД через Docker exec
+// DockerDBMonitor мониторинг PostgreSQL через Docker exec
 type DockerDBMonitor struct {
-	containerName string
-	user          string
-	password      string
-	database      string
-	port          string
+	dockerExec
+	user     string
+	password string
+	database string
+	port     string
+	counters counterTracker
+
+	slowQuerySettings
+	schemaSettings
 }
 
 // NewDockerDBMonitor создает монитор для подключения через Docker
 func NewDockerDBMonitor(containerName, user, password, database, port string) *DockerDBMonitor {
 	return &DockerDBMonitor{
-		containerName: containerName,
-		user:          user,
-		password:      password,
-		database:      database,
-		port:          port,
+		dockerExec: dockerExec{containerName: containerName},
+		user:       user,
+		password:   password,
+		database:   database,
+		port:       port,
 	}
 }
 
-// Connect проверяет доступность контейнера
+// Connect подключается к Docker и проверяет, что контейнер запущен
 func (d *DockerDBMonitor) Connect(connectionString string) error {
-	// Проверяем, что контейнер существует и запущен
-	cmd := exec.Command("docker", "inspect", d.containerName)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("container %s not found or not running: %w", d.containerName, err)
-	}
-	return nil
+	return d.connect()
 }
 
 // GetStats получает статистику через Docker exec
@@ -74,6 +71,20 @@ func (d *DockerDBMonitor) GetStats() (*DBStats, error) {
 	}
 	stats.MaxConnections = maxConnections
 
+	// Счетчики транзакций для расчета скорости и задержки
+	rows, err := d.execRows(postgresCountersQuery, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction counters: %w", err)
+	}
+	if len(rows) > 0 {
+		sample := counterSample{at: time.Now()}
+		sample.queries, _ = strconv.ParseFloat(rows[0][0], 64)
+		sample.errors, _ = strconv.ParseFloat(rows[0][1], 64)
+		activeTimeMs, _ := strconv.ParseFloat(rows[0][2], 64)
+		sample.busyTime = time.Duration(activeTimeMs * float64(time.Millisecond))
+		d.counters.update(sample, stats)
+	}
+
 	// Размер базы данных
 	dbSize, err := d.execQueryString(`
 		SELECT pg_size_pretty(pg_database_size(current_database()))
@@ -83,23 +94,13 @@ func (d *DockerDBMonitor) GetStats() (*DBStats, error) {
 	}
 	stats.DatabaseSize = dbSize
 
-	// Количество таблиц
-	tableCount, err := d.execQuery(`
-		SELECT count(*) 
-		FROM information_schema.tables 
-		WHERE table_schema = 'public'
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get table count: %w", err)
-	}
-	stats.TableCount = tableCount
-
 	// Получаем информацию о таблицах
 	tables, err := d.GetTables()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tables: %w", err)
 	}
 	stats.Tables = tables
+	stats.TableCount = len(tables)
 
 	// Получаем медленные запросы
 	slowQueries, err := d.GetSlowQueries()
@@ -113,233 +114,455 @@ func (d *DockerDBMonitor) GetStats() (*DBStats, error) {
 
 // GetTables получает информацию о таблицах
 func (d *DockerDBMonitor) GetTables() ([]TableInfo, error) {
-	query := `
-		SELECT 
-			t.table_name,
-			COALESCE(pg_size_pretty(pg_total_relation_size(c.oid)), '0 bytes') as size,
-			COALESCE(i.index_count, 0) as index_count
-		FROM information_schema.tables t
-		LEFT JOIN pg_class c ON c.relname = t.table_name
-		LEFT JOIN (
-			SELECT 
-				tablename,
-				count(*) as index_count
-			FROM pg_indexes 
-			WHERE schemaname = 'public'
-			GROUP BY tablename
-		) i ON i.tablename = t.table_name
-		WHERE t.table_schema = 'public'
-		ORDER BY t.table_name
-		LIMIT 20;
-	`
-
-	output, err := d.execQueryRaw(query)
+	rows, err := d.execRows(postgresTablesQuery(d.Schema()), 8)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tables: %w", err)
 	}
+	tables := postgresTables(rows)
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	var tables []TableInfo
+	// Состояние vacuum дополнительное: без pg_stat_progress_vacuum (до 9.6) таблицы показываются без него
+	if health, err := d.execRows(postgresTableHealthQuery(d.Schema()), 17); err == nil {
+		attachTableHealth(tables, postgresTableHealth(health))
+	}
 
-	// Пропускаем заголовок и разделители
-	for i := 1; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		if line == "" || strings.Contains(line, "----") || strings.Contains(line, "(20 rows)") {
-			continue
-		}
+	return tables, nil
+}
 
-		// Ищем строки, которые содержат данные (не заголовки)
-		if !strings.Contains(line, "table_name") && !strings.Contains(line, "size") &&
-			!strings.Contains(line, "index_count") && !strings.Contains(line, "----") && !strings.Contains(line, "rows)") {
-
-			// Разбиваем по | для правильного парсинга PostgreSQL вывода
-			columns := strings.Split(line, "|")
-			if len(columns) >= 3 {
-				tableName := strings.TrimSpace(columns[0])
-				sizeStr := strings.TrimSpace(columns[1])
-				indexCountStr := strings.TrimSpace(columns[2])
-
-				indexCount, _ := strconv.Atoi(indexCountStr)
-				size := sizeStr
-
-				table := TableInfo{
-					Name:       tableName,
-					Size:       size,
-					Indexes:    indexCount,
-					LastUpdate: time.Now(),
-				}
-
-				tables = append(tables, table)
-			}
-		}
+// CountRows точно считает строки таблицы через COUNT(*); на больших таблицах это долго
+func (d *DockerDBMonitor) CountRows(table TableInfo) (int64, error) {
+	count, err := d.execQueryString(countRowsQuery("postgresql", table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
 	}
+	return parseInt64(count), nil
+}
 
-	return tables, nil
+// ListSchemas возвращает пользовательские схемы базы
+func (d *DockerDBMonitor) ListSchemas() ([]string, error) {
+	rows, err := d.execRows(postgresSchemasQuery, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schemas: %w", err)
+	}
+	return firstColumn(rows), nil
 }
 
 // GetSlowQueries получает медленные запросы
 func (d *DockerDBMonitor) GetSlowQueries() ([]SlowQuery, error) {
 	// Проверяем, включен ли pg_stat_statements
-	exists, err := d.execQueryString(`
-		SELECT EXISTS (
-			SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements'
-		)
-	`)
+	exists, err := d.execQueryString(postgresStatementsExistsQuery)
 	if err != nil || exists != "t" {
 		return []SlowQuery{}, nil
 	}
 
+	version, err := d.execQuery("SHOW server_version_num")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	rows, err := d.execRows(postgresSlowQueriesQuery(version, d.SlowQueryOptions()), 13)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query slow queries: %w", err)
+	}
+
+	return postgresSlowQueries(rows), nil
+}
+
+// ResetSlowQueries сбрасывает статистику pg_stat_statements
+func (d *DockerDBMonitor) ResetSlowQueries() error {
+	if _, err := d.execPsql(postgresResetStatementsQuery); err != nil {
+		return fmt.Errorf("failed to reset pg_stat_statements: %w", err)
+	}
+	return nil
+}
+
+// GetConnections получает список соединений из pg_stat_activity
+func (d *DockerDBMonitor) GetConnections() ([]ConnectionInfo, error) {
 	query := `
 		SELECT 
-			query,
-			mean_time,
-			calls,
-			usename
-		FROM pg_stat_statements 
-		JOIN pg_user ON pg_stat_statements.userid = pg_user.usesysid
-		WHERE mean_time > 1000
-		ORDER BY mean_time DESC 
-		LIMIT 10
+			pid,
+			COALESCE(usename, ''),
+			COALESCE(host(client_addr), 'local'),
+			COALESCE(state, ''),
+			COALESCE(EXTRACT(EPOCH FROM now() - query_start), 0)::bigint,
+			COALESCE(wait_event_type || ':' || wait_event, ''),
+			COALESCE(translate(query, E'\n\r\t', '   '), '')
+		FROM pg_stat_activity 
+		WHERE pid <> pg_backend_pid()
+		  AND backend_type = 'client backend'
+		ORDER BY query_start NULLS LAST;
 	`
 
-	output, err := d.execQueryRaw(query)
+	rows, err := d.execRows(query, 7)
 	if err != nil {
-		return []SlowQuery{}, nil
+		return nil, fmt.Errorf("failed to query connections: %w", err)
 	}
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	var slowQueries []SlowQuery
+	var connections []ConnectionInfo
+	for _, columns := range rows {
+		ageSec, _ := strconv.ParseInt(columns[4], 10, 64)
+
+		connections = append(connections, ConnectionInfo{
+			ID:         columns[0],
+			User:       columns[1],
+			ClientAddr: columns[2],
+			State:      columns[3],
+			QueryAge:   time.Duration(ageSec) * time.Second,
+			WaitEvent:  columns[5],
+			Query:      columns[6],
+		})
+	}
 
-	// Пропускаем заголовок
-	for i := 1; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
-		}
+	return connections, nil
+}
 
-		parts := strings.Fields(line)
-		if len(parts) < 4 {
-			continue
-		}
+// GetLocks получает сессии, участвующие в блокировках
+func (d *DockerDBMonitor) GetLocks() ([]LockInfo, error) {
+	rows, err := d.execRows(postgresLocksQuery, 9)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query locks: %w", err)
+	}
 
-		queryText := parts[0]
-		meanTime, _ := strconv.ParseFloat(parts[1], 64)
-		username := parts[3]
+	var locks []LockInfo
+	for _, columns := range rows {
+		waitSec, _ := strconv.ParseInt(columns[7], 10, 64)
+
+		locks = append(locks, LockInfo{
+			ID:           columns[0],
+			User:         columns[1],
+			State:        columns[2],
+			BlockedBy:    splitIDs(columns[3]),
+			LockType:     columns[4],
+			Mode:         columns[5],
+			Relation:     columns[6],
+			WaitDuration: time.Duration(waitSec) * time.Second,
+			Query:        columns[8],
+		})
+	}
 
-		// Обрезаем длинный запрос
-		if len(queryText) > 100 {
-			queryText = queryText[:97] + "..."
-		}
+	return locks, nil
+}
+
+// GetReplication получает реплики, состояние standby и слоты репликации через psql
+func (d *DockerDBMonitor) GetReplication() (*ReplicationStatus, error) {
+	inRecovery, err := d.execQueryString(postgresRecoveryQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery status: %w", err)
+	}
+
+	replicas, err := d.execRows(postgresReplicasQuery, 9)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replicas: %w", err)
+	}
 
-		slowQuery := SlowQuery{
-			Query:     queryText,
-			Duration:  time.Duration(meanTime * float64(time.Millisecond)),
-			Timestamp: time.Now(),
-			User:      username,
+	var standby []string
+	if parseBool(inRecovery) {
+		rows, err := d.execRows(postgresStandbyQuery, 6)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query standby status: %w", err)
 		}
+		if len(rows) > 0 {
+			standby = rows[0]
+		}
+	}
+
+	slots, err := d.execRows(postgresSlotsQuery, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replication slots: %w", err)
+	}
+
+	return postgresReplication(inRecovery, replicas, standby, slots), nil
+}
+
+// GetIndexes получает индексы со статистикой сканирований через psql и анализирует их
+func (d *DockerDBMonitor) GetIndexes() (*IndexReport, error) {
+	rows, err := d.execRows(postgresIndexesQuery, 12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
 
-		slowQueries = append(slowQueries, slowQuery)
+	scans, err := d.execRows(postgresTableScansQuery, 5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table scans: %w", err)
 	}
 
-	return slowQueries, nil
+	return buildIndexReport("postgresql", postgresIndexes(rows), tableScans(scans)), nil
+}
+
+// Explain строит план запроса; с analyze запрос выполняется в транзакции, которая затем откатывается
+func (d *DockerDBMonitor) Explain(query string, analyze bool) (*ExplainPlan, error) {
+	version, err := d.execQuery("SHOW server_version_num")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	statement, generic, err := postgresExplainStatement(query, analyze, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// При ошибке psql завершается (ON_ERROR_STOP), и незавершенная транзакция откатывается сервером
+	raw, err := d.execQueryString("BEGIN;\n" + statement + ";\nROLLBACK;")
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	plan, err := parsePostgresPlan(raw)
+	if err != nil {
+		return nil, err
+	}
+	plan.Query = query
+	plan.Generic = generic
+	return plan, nil
 }
 
 // KillConnection завершает соединение
 func (d *DockerDBMonitor) KillConnection(connectionID string) error {
-	query := fmt.Sprintf("SELECT pg_terminate_backend(%s)", connectionID)
-	_, err := d.execQueryRaw(query)
+	pid, err := strconv.Atoi(connectionID)
 	if err != nil {
+		return fmt.Errorf("invalid connection id %q: %w", connectionID, err)
+	}
+
+	query := fmt.Sprintf("SELECT pg_terminate_backend(%d)", pid)
+	if _, err := d.execPsql(query); err != nil {
 		return fmt.Errorf("failed to kill connection %s: %w", connectionID, err)
 	}
 	return nil
 }
 
-// Close закрывает соединение (не нужно для Docker exec)
+// Close закрывает клиент Docker
 func (d *DockerDBMonitor) Close() error {
-	return nil
+	return d.close()
 }
 
-// execQuery выполняет запрос и возвращает int
-func (d *DockerDBMonitor) execQuery(query string) (int, error) {
-	output, err := d.execQueryRaw(query)
-	if err != nil {
-		return 0, err
-	}
+// Разделители полей и записей для вывода psql: управляющие символы ASCII не встречаются
+// в данных, поэтому кавычки, "|" и переводы строк в запросах не ломают разбор
+const (
+	psqlFieldSeparator  = "\x1f"
+	psqlRecordSeparator = "\x1e"
+)
 
-	result := strings.TrimSpace(output)
-	lines := strings.Split(result, "\n")
+// dockerExecTimeout ограничивает время одного запроса в контейнере
+const dockerExecTimeout = 30 * time.Second
 
-	// Ищем строку с результатом (после заголовка)
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.Contains(line, "----") || strings.Contains(line, "count") {
+// parseUnalignedRows разбирает вывод psql -A -t -F US -R RS на строки и колонки.
+// psql завершает последнюю запись переводом строки, а не разделителем записей
+func parseUnalignedRows(output string, columnCount int) [][]string {
+	output = strings.TrimSuffix(output, "\n")
+	if output == "" {
+		return nil
+	}
+
+	var rows [][]string
+	for _, record := range strings.Split(output, psqlRecordSeparator) {
+		columns := strings.Split(record, psqlFieldSeparator)
+		if len(columns) != columnCount {
 			continue
 		}
-		// Если это число, возвращаем его
-		if value, err := strconv.Atoi(line); err == nil {
-			return value, nil
-		}
+		rows = append(rows, columns)
 	}
 
-	return 0, fmt.Errorf("no numeric result found in output")
+	return rows
 }
 
-// execQueryString выполняет запрос и возвращает string
+// execRows выполняет запрос и возвращает строки с ожидаемым числом колонок
+func (d *DockerDBMonitor) execRows(query string, columnCount int) ([][]string, error) {
+	output, err := d.execPsql(query)
+	if err != nil {
+		return nil, err
+	}
+	return parseUnalignedRows(output, columnCount), nil
+}
+
+// execQueryString выполняет запрос и возвращает значение первой колонки первой строки
 func (d *DockerDBMonitor) execQueryString(query string) (string, error) {
-	output, err := d.execQueryRaw(query)
+	rows, err := d.execRows(query, 1)
 	if err != nil {
 		return "", err
 	}
+	if len(rows) == 0 {
+		return "", fmt.Errorf("no result found in output")
+	}
+	return strings.TrimSpace(rows[0][0]), nil
+}
 
-	result := strings.TrimSpace(output)
-	lines := strings.Split(result, "\n")
+// execQuery выполняет запрос и возвращает int
+func (d *DockerDBMonitor) execQuery(query string) (int, error) {
+	value, err := d.execQueryString(query)
+	if err != nil {
+		return 0, err
+	}
 
-	// Ищем строку с результатом (после заголовка)
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.Contains(line, "----") || strings.Contains(line, "pg_size_pretty") ||
-			strings.Contains(line, "max_connections") || strings.Contains(line, "pg_database_size") {
-			continue
-		}
-		// Если это не пустая строка и не заголовок, возвращаем её
-		if line != "" {
-			return line, nil
-		}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("no numeric result found in output: %w", err)
+	}
+	return result, nil
+}
+
+// execPsql выполняет запрос через psql в контейнере. Запрос передается в stdin,
+// пароль - в окружении exec, поэтому он не виден в списке процессов
+func (d *DockerDBMonitor) execPsql(query string) (string, error) {
+	env := []string{"PGPASSWORD=" + d.password}
+	return d.exec(d.psqlCommand(), env, query)
+}
+
+// psqlCommand аргументы psql. Без -h psql идет через unix-сокет, а порт без -p берет из PGPORT
+// контейнера, поэтому -p передается, только если порт задан явно
+func (d *DockerDBMonitor) psqlCommand() []string {
+	cmd := []string{
+		"psql", "-X", "-q", "-A", "-t",
+		"-F", psqlFieldSeparator, "-R", psqlRecordSeparator,
+		"-v", "ON_ERROR_STOP=1",
+		"-U", d.user, "-d", d.database,
+	}
+	if d.port != "" {
+		cmd = append(cmd, "-p", d.port)
+	}
+	return cmd
+}
+
+// DetectDockerEngine определяет СУБД в контейнере по имени образа, а затем по переменным окружения
+// официальных образов: postgresql, mysql или mariadb
+func DetectDockerEngine(containerName string) (string, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return "", fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerExecTimeout)
+	defer cancel()
+
+	info, err := cli.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return "", fmt.Errorf("container %s not found: %w", containerName, err)
+	}
+
+	var image string
+	var env []string
+	if info.Config != nil {
+		image = info.Config.Image
+		env = info.Config.Env
+	}
+
+	if engine := engineFromImage(image); engine != "" {
+		return engine, nil
+	}
+	if engine := engineFromEnv(env); engine != "" {
+		return engine, nil
 	}
 
-	return "", fmt.Errorf("no result found in output")
+	return "", fmt.Errorf("cannot detect database engine of container %s (image %q), use --engine", containerName, image)
 }
 
-// execQueryRaw выполняет запрос через Docker exec
-func (d *DockerDBMonitor) execQueryRaw(query string) (string, error) {
-	// Создаем временный файл с запросом
-	queryFile := fmt.Sprintf("/tmp/query_%d.sql", time.Now().Unix())
+// engineFromImage определяет СУБД по имени образа, например "postgres:16", "bitnami/mysql:8.0", "mariadb:11"
+func engineFromImage(image string) string {
+	name := strings.ToLower(image)
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	name, _, _ = strings.Cut(name, ":")
+	name, _, _ = strings.Cut(name, "@")
+
+	switch {
+	case strings.Contains(name, "mariadb"):
+		return "mariadb"
+	case strings.Contains(name, "mysql"), strings.Contains(name, "percona"):
+		return "mysql"
+	case strings.Contains(name, "postgres"), strings.Contains(name, "postgis"), strings.Contains(name, "timescale"):
+		return "postgresql"
+	}
+	return ""
+}
 
-	// Создаем файл в контейнере
-	createFileCmd := exec.Command("docker", "exec", d.containerName, "bash", "-c",
-		fmt.Sprintf("echo \"%s\" > %s", query, queryFile))
+// dockerExec выполняет команды в контейнере через Docker Engine API
+type dockerExec struct {
+	containerName string
+	cli           *client.Client
+}
 
-	if err := createFileCmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to create query file: %w", err)
+// connect создает клиент Docker и проверяет, что контейнер запущен
+func (e *dockerExec) connect() error {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
 	}
 
-	// Выполняем запрос из файла с таймаутом
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), dockerExecTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "docker", "exec", d.containerName, "bash", "-c",
-		fmt.Sprintf("PGPASSWORD=%s psql -U %s -d %s -f %s --no-psqlrc",
-			d.password, d.user, d.database, queryFile))
+	info, err := cli.ContainerInspect(ctx, e.containerName)
+	if err != nil {
+		cli.Close()
+		return fmt.Errorf("container %s not found: %w", e.containerName, err)
+	}
+	if info.State == nil || !info.State.Running {
+		cli.Close()
+		return fmt.Errorf("container %s is not running", e.containerName)
+	}
 
-	output, err := cmd.CombinedOutput()
+	e.cli = cli
+	return nil
+}
+
+func (e *dockerExec) close() error {
+	if e.cli == nil {
+		return nil
+	}
+	return e.cli.Close()
+}
+
+// exec запускает команду в контейнере через Docker Engine API и возвращает stdout
+func (e *dockerExec) exec(cmd, env []string, stdin string) (string, error) {
+	if e.cli == nil {
+		return "", fmt.Errorf("docker monitor is not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerExecTimeout)
+	defer cancel()
+
+	created, err := e.cli.ContainerExecCreate(ctx, e.containerName, container.ExecOptions{
+		Cmd:          cmd,
+		Env:          env,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	resp, err := e.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
 	if err != nil {
-		return "", fmt.Errorf("docker exec failed: %w, output: %s", err, string(output))
+		return "", fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer resp.Close()
+
+	// Пишем stdin параллельно с чтением, чтобы большой вывод не заблокировал запись
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(resp.Conn, stdin)
+		if closeErr := resp.CloseWrite(); err == nil {
+			err = closeErr
+		}
+		writeErr <- err
+	}()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		return "", fmt.Errorf("failed to read exec output: %w", err)
+	}
+	if err := <-writeErr; err != nil {
+		return "", fmt.Errorf("failed to write query: %w", err)
 	}
 
-	// Удаляем временный файл
-	cleanupCmd := exec.Command("docker", "exec", d.containerName, "rm", queryFile)
-	cleanupCmd.Run() // игнорируем ошибку очистки
+	inspect, err := e.cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		return "", fmt.Errorf("%s exited with code %d: %s", cmd[0], inspect.ExitCode, strings.TrimSpace(stderr.String()))
+	}
 
-	result := string(output)
-	return result, nil
+	return stdout.String(), nil
 }
@@ -0,0 +1,411 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DockerMySQLMonitor мониторинг MySQL/MariaDB через Docker exec клиентом mysql (или mariadb) в контейнере
+type DockerMySQLMonitor struct {
+	dockerExec
+	client   string
+	user     string
+	password string
+	database string
+	port     string
+	counters counterTracker
+
+	slowQuerySettings
+	schemaSettings
+}
+
+// NewDockerMySQLMonitor создает монитор MySQL/MariaDB; client - имя клиента в образе (mysql или mariadb),
+// пустой port - подключение через сокет сервера в контейнере
+func NewDockerMySQLMonitor(containerName, client, user, password, database, port string) *DockerMySQLMonitor {
+	return &DockerMySQLMonitor{
+		dockerExec: dockerExec{containerName: containerName},
+		client:     client,
+		user:       user,
+		password:   password,
+		database:   database,
+		port:       port,
+	}
+}
+
+// Connect подключается к Docker и проверяет, что контейнер запущен
+func (d *DockerMySQLMonitor) Connect(connectionString string) error {
+	return d.connect()
+}
+
+// GetStats получает статистику MySQL через Docker exec
+func (d *DockerMySQLMonitor) GetStats() (*DBStats, error) {
+	stats := &DBStats{
+		LastUpdate: time.Now(),
+	}
+
+	// Активные соединения
+	activeConnections, err := d.execVariable("SHOW GLOBAL STATUS LIKE 'Threads_connected'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active connections: %w", err)
+	}
+	stats.ActiveConnections, _ = strconv.Atoi(activeConnections)
+
+	// Максимальное количество соединений
+	maxConnections, err := d.execVariable("SHOW VARIABLES LIKE 'max_connections'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max connections: %w", err)
+	}
+	stats.MaxConnections, _ = strconv.Atoi(maxConnections)
+
+	// Счетчики запросов для расчета скорости и задержки
+	sample, err := d.sampleCounters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement counters: %w", err)
+	}
+	d.counters.update(sample, stats)
+
+	// Размер базы данных
+	dbSize, err := d.execValue(mysqlSizeQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+	stats.DatabaseSize = dbSize + " MB"
+
+	// Получаем информацию о таблицах
+	tables, err := d.GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tables: %w", err)
+	}
+	stats.Tables = tables
+	stats.TableCount = len(tables)
+
+	// Получаем медленные запросы
+	slowQueries, err := d.GetSlowQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slow queries: %w", err)
+	}
+	stats.SlowQueries = slowQueries
+
+	return stats, nil
+}
+
+// sampleCounters читает накопительные счетчики так же, как MySQLMonitor
+func (d *DockerMySQLMonitor) sampleCounters() (counterSample, error) {
+	sample := counterSample{at: time.Now()}
+
+	rows, err := d.execRows(mysqlCountersQuery, 3)
+	if err == nil && len(rows) > 0 {
+		sample.queries, _ = strconv.ParseFloat(rows[0][0], 64)
+		sample.errors, _ = strconv.ParseFloat(rows[0][1], 64)
+		busySec, _ := strconv.ParseFloat(rows[0][2], 64)
+		if sample.queries > 0 {
+			sample.busyTime = time.Duration(busySec * float64(time.Second))
+			return sample, nil
+		}
+	}
+
+	rows, err = d.execRows(mysqlStatusCountersQuery, 2)
+	if err != nil {
+		return sample, err
+	}
+	for _, columns := range rows {
+		value, _ := strconv.ParseFloat(columns[1], 64)
+		switch columns[0] {
+		case "Questions":
+			sample.queries = value
+		case "Com_rollback":
+			sample.errors = value
+		}
+	}
+
+	return sample, nil
+}
+
+// GetTables получает информацию о таблицах
+func (d *DockerMySQLMonitor) GetTables() ([]TableInfo, error) {
+	rows, err := d.execRows(mysqlTablesQuery(d.Schema()), 6)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	return mysqlTables(rows), nil
+}
+
+// CountRows точно считает строки таблицы через COUNT(*): table_rows для InnoDB лишь оценка
+func (d *DockerMySQLMonitor) CountRows(table TableInfo) (int64, error) {
+	count, err := d.execValue(countRowsQuery("mysql", table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return parseInt64(count), nil
+}
+
+// ListSchemas возвращает базы сервера, доступные для просмотра
+func (d *DockerMySQLMonitor) ListSchemas() ([]string, error) {
+	rows, err := d.execRows(mysqlSchemasQuery, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query databases: %w", err)
+	}
+	return firstColumn(rows), nil
+}
+
+// GetSlowQueries получает медленные запросы из performance_schema
+func (d *DockerMySQLMonitor) GetSlowQueries() ([]SlowQuery, error) {
+	// Проверяем, включен ли slow query log
+	slowQueryLog, err := d.execVariable("SHOW VARIABLES LIKE 'slow_query_log'")
+	if err != nil || slowQueryLog != "ON" {
+		return []SlowQuery{}, nil
+	}
+
+	// query_sample_text нет в MySQL 5.7 и MariaDB
+	opts := d.SlowQueryOptions()
+	rows, err := d.execRows(mysqlSlowQueriesQuery(opts, true), 10)
+	if err != nil {
+		rows, err = d.execRows(mysqlSlowQueriesQuery(opts, false), 10)
+	}
+	if err != nil {
+		return []SlowQuery{}, nil // Performance schema может быть недоступен
+	}
+
+	return mysqlSlowQueries(rows), nil
+}
+
+// ResetSlowQueries очищает статистику по digest в performance_schema
+func (d *DockerMySQLMonitor) ResetSlowQueries() error {
+	if _, err := d.execMySQL(mysqlResetStatementsQuery); err != nil {
+		return fmt.Errorf("failed to reset statement statistics: %w", err)
+	}
+	return nil
+}
+
+// GetConnections получает список соединений из information_schema.PROCESSLIST
+func (d *DockerMySQLMonitor) GetConnections() ([]ConnectionInfo, error) {
+	rows, err := d.execRows(mysqlConnectionsQuery, 7)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connections: %w", err)
+	}
+
+	var connections []ConnectionInfo
+	for _, columns := range rows {
+		timeSec, _ := strconv.ParseInt(columns[5], 10, 64)
+
+		connections = append(connections, ConnectionInfo{
+			ID:         columns[0],
+			User:       columns[1],
+			ClientAddr: columns[2],
+			State:      columns[3],
+			Query:      columns[4],
+			QueryAge:   time.Duration(timeSec) * time.Second,
+			WaitEvent:  columns[6],
+		})
+	}
+
+	return connections, nil
+}
+
+// GetLocks получает цепочки ожидания блокировок InnoDB
+func (d *DockerMySQLMonitor) GetLocks() ([]LockInfo, error) {
+	rows, err := d.execRows(mysqlLockWaitsQuery, 8)
+	if err != nil {
+		rows, err = d.execRows(mysqlDataLockWaitsQuery, 8)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query lock waits: %w", err)
+		}
+	}
+
+	var waits []mysqlLockWait
+	for _, columns := range rows {
+		waitSec, _ := strconv.ParseInt(columns[5], 10, 64)
+
+		waits = append(waits, mysqlLockWait{
+			waitingID:     columns[0],
+			blockingID:    columns[1],
+			table:         columns[2],
+			lockType:      columns[3],
+			mode:          columns[4],
+			waitSec:       waitSec,
+			waitingQuery:  columns[6],
+			blockingQuery: columns[7],
+		})
+	}
+
+	return mysqlLocksFromWaits(waits), nil
+}
+
+// GetReplication получает состояние реплики и подключенные реплики через вертикальный вывод (\G)
+func (d *DockerMySQLMonitor) GetReplication() (*ReplicationStatus, error) {
+	replicaStatus, err := d.execVertical("SHOW REPLICA STATUS", "SHOW SLAVE STATUS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replica status: %w", err)
+	}
+
+	hosts, err := d.execVertical("SHOW REPLICAS", "SHOW SLAVE HOSTS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replicas: %w", err)
+	}
+
+	return mysqlReplication(replicaStatus, hosts), nil
+}
+
+// execVertical выполняет первый поддерживаемый сервером запрос из списка с выводом по полям
+func (d *DockerMySQLMonitor) execVertical(queries ...string) ([]map[string]string, error) {
+	var lastErr error
+	for _, query := range queries {
+		output, err := d.execMySQL(query + "\\G")
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return parseVerticalRows(output), nil
+	}
+	return nil, lastErr
+}
+
+// GetIndexes получает индексы текущей базы через клиент mysql и анализирует их
+func (d *DockerMySQLMonitor) GetIndexes() (*IndexReport, error) {
+	rows, err := d.execRows(mysqlIndexesQuery, 9)
+	if err != nil {
+		rows, err = d.execRows(mysqlIndexesFallbackQuery, 9)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query indexes: %w", err)
+		}
+	}
+
+	// Ошибка performance_schema не мешает остальному анализу
+	scans, _ := d.execRows(mysqlTableScansQuery, 5)
+
+	return buildIndexReport("mysql", mysqlIndexes(rows), tableScans(scans)), nil
+}
+
+// Explain строит план запроса; с analyze запрос выполняется в транзакции, которая затем откатывается
+func (d *DockerMySQLMonitor) Explain(query string, analyze bool) (*ExplainPlan, error) {
+	version, err := d.execValue("SELECT VERSION()")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	statement, setup, err := mysqlExplainStatement(query, analyze, version)
+	if err != nil {
+		return nil, err
+	}
+	if setup != "" {
+		setup += ";\n"
+	}
+
+	// При ошибке клиент завершается, и незавершенная транзакция откатывается сервером
+	raw, err := d.execValue(setup + "START TRANSACTION;\n" + statement + ";\nROLLBACK;")
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	plan, err := parseMySQLPlan(raw)
+	if err != nil {
+		return nil, err
+	}
+	plan.Query = query
+	return plan, nil
+}
+
+// KillConnection завершает соединение по ID
+func (d *DockerMySQLMonitor) KillConnection(connectionID string) error {
+	id, err := strconv.ParseInt(connectionID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid connection id %q: %w", connectionID, err)
+	}
+
+	if _, err := d.execMySQL(fmt.Sprintf("KILL %d", id)); err != nil {
+		return fmt.Errorf("failed to kill connection %s: %w", connectionID, err)
+	}
+	return nil
+}
+
+// Close закрывает клиент Docker
+func (d *DockerMySQLMonitor) Close() error {
+	return d.close()
+}
+
+// parseBatchRows разбирает вывод mysql --batch --skip-column-names: строки через \n, колонки через \t.
+// В batch-режиме клиент экранирует \n, \t, \ и NUL внутри значений, NULL выводится как "NULL"
+func parseBatchRows(output string, columnCount int) [][]string {
+	output = strings.TrimSuffix(output, "\n")
+	if output == "" {
+		return nil
+	}
+
+	var rows [][]string
+	for _, line := range strings.Split(output, "\n") {
+		columns := strings.Split(line, "\t")
+		if len(columns) != columnCount {
+			continue
+		}
+		for i := range columns {
+			columns[i] = unescapeBatchValue(columns[i])
+		}
+		rows = append(rows, columns)
+	}
+
+	return rows
+}
+
+var batchUnescaper = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\0`, "\x00", `\\`, `\`)
+
+func unescapeBatchValue(value string) string {
+	if value == "NULL" {
+		return ""
+	}
+	return batchUnescaper.Replace(value)
+}
+
+// execRows выполняет запрос и возвращает строки с ожидаемым числом колонок
+func (d *DockerMySQLMonitor) execRows(query string, columnCount int) ([][]string, error) {
+	output, err := d.execMySQL(query)
+	if err != nil {
+		return nil, err
+	}
+	return parseBatchRows(output, columnCount), nil
+}
+
+// execValue выполняет запрос и возвращает значение первой колонки первой строки
+func (d *DockerMySQLMonitor) execValue(query string) (string, error) {
+	rows, err := d.execRows(query, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "", fmt.Errorf("no result found in output")
+	}
+	return rows[0][0], nil
+}
+
+// execVariable выполняет SHOW ... LIKE и возвращает значение переменной
+func (d *DockerMySQLMonitor) execVariable(query string) (string, error) {
+	rows, err := d.execRows(query, 2)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "", fmt.Errorf("no result found in output")
+	}
+	return rows[0][1], nil
+}
+
+// execMySQL выполняет запрос клиентом mysql в контейнере. Запрос передается в stdin,
+// пароль - через MYSQL_PWD в окружении exec
+func (d *DockerMySQLMonitor) execMySQL(query string) (string, error) {
+	env := []string{"MYSQL_PWD=" + d.password}
+	return d.exec(d.mysqlCommand(), env, query)
+}
+
+// mysqlCommand аргументы клиента. С localhost клиент идет через сокет и порт игнорирует,
+// поэтому явный порт передается вместе с --protocol=TCP
+func (d *DockerMySQLMonitor) mysqlCommand() []string {
+	cmd := []string{d.client, "--batch", "--skip-column-names", "-u", d.user}
+	if d.database != "" {
+		cmd = append(cmd, "-D", d.database)
+	}
+	if d.port != "" {
+		cmd = append(cmd, "--protocol=TCP", "-P", d.port)
+	}
+	return cmd
+}
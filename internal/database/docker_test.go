@@ -0,0 +1,45 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPsqlCommandPort(t *testing.T) {
+	tests := []struct {
+		port     string
+		wantPort bool
+	}{
+		{"", false}, // порт из PGPORT контейнера
+		{"6432", true},
+	}
+
+	for _, tt := range tests {
+		monitor := NewDockerDBMonitor("pg", "app", "secret", "appdb", tt.port)
+		cmd := monitor.psqlCommand()
+
+		i := slices.Index(cmd, "-p")
+		if !tt.wantPort {
+			if i >= 0 {
+				t.Errorf("port %q: psql got -p: %q", tt.port, cmd)
+			}
+			continue
+		}
+		if i < 0 || i+1 >= len(cmd) || cmd[i+1] != tt.port {
+			t.Errorf("port %q: psql command %q, want -p %s", tt.port, cmd, tt.port)
+		}
+	}
+}
+
+func TestMySQLCommandPort(t *testing.T) {
+	socket := NewDockerMySQLMonitor("db", "mysql", "root", "secret", "shop", "").mysqlCommand()
+	if slices.Contains(socket, "-P") || slices.Contains(socket, "--protocol=TCP") {
+		t.Errorf("without port: %q, want socket connection", socket)
+	}
+
+	tcp := NewDockerMySQLMonitor("db", "mariadb", "root", "secret", "shop", "3307").mysqlCommand()
+	i := slices.Index(tcp, "-P")
+	if tcp[0] != "mariadb" || !slices.Contains(tcp, "--protocol=TCP") || i < 0 || i+1 >= len(tcp) || tcp[i+1] != "3307" {
+		t.Errorf("with port: %q, want --protocol=TCP -P 3307", tcp)
+	}
+}
@@ -0,0 +1,689 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExplainPlan план запроса из EXPLAIN (FORMAT JSON) в PostgreSQL или EXPLAIN FORMAT=JSON в MySQL
+type ExplainPlan struct {
+	Query         string
+	Analyzed      bool // план получен с ANALYZE и содержит фактические строки и время
+	Generic       bool // обобщенный план для запроса с параметрами ($1, ...)
+	Root          *PlanNode
+	PlanningTime  time.Duration // -1, если неизвестно
+	ExecutionTime time.Duration // -1, если неизвестно
+	Raw           string        // исходный JSON
+}
+
+// PlanNode узел плана
+type PlanNode struct {
+	Operation     string
+	Relation      string
+	Details       []string // индекс, условия, ключи сортировки
+	StartupCost   float64
+	TotalCost     float64 // стоимость с учетом дочерних узлов
+	SelfCost      float64
+	EstimatedRows float64
+	ActualRows    float64 // -1, если план без ANALYZE
+	ActualTime    float64 // мс за все циклы с учетом дочерних узлов; -1, если неизвестно
+	SelfTime      float64 // мс без дочерних узлов; -1, если неизвестно
+	Loops         int64
+	Children      []*PlanNode
+}
+
+// PlanRow узел плана в плоском списке для вывода дерева
+type PlanRow struct {
+	Node  *PlanNode
+	Depth int
+}
+
+// Rows разворачивает дерево в список; потомки свернутых узлов пропускаются
+func (p *ExplainPlan) Rows(collapsed map[*PlanNode]bool) []PlanRow {
+	var rows []PlanRow
+	var walk func(node *PlanNode, depth int)
+	walk = func(node *PlanNode, depth int) {
+		rows = append(rows, PlanRow{Node: node, Depth: depth})
+		if collapsed[node] {
+			return
+		}
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	if p.Root != nil {
+		walk(p.Root, 0)
+	}
+	return rows
+}
+
+// MostExpensive самый дорогой узел: по собственному времени, если план с ANALYZE, иначе по собственной стоимости
+func (p *ExplainPlan) MostExpensive() *PlanNode {
+	var best *PlanNode
+	byTime := false
+	for _, row := range p.Rows(nil) {
+		if row.Node.SelfTime >= 0 {
+			byTime = true
+			break
+		}
+	}
+
+	for _, row := range p.Rows(nil) {
+		node := row.Node
+		switch {
+		case best == nil:
+			best = node
+		case byTime && node.SelfTime > best.SelfTime:
+			best = node
+		case !byTime && node.SelfCost > best.SelfCost:
+			best = node
+		}
+	}
+	return best
+}
+
+// Summary строка узла: операция, стоимость, оценка строк и фактические значения
+func (n *PlanNode) Summary() string {
+	var sb strings.Builder
+	sb.WriteString(n.Operation)
+	if n.Relation != "" {
+		sb.WriteString(" on " + n.Relation)
+	}
+
+	if n.StartupCost > 0 {
+		sb.WriteString(fmt.Sprintf("  cost=%.2f..%.2f", n.StartupCost, n.TotalCost))
+	} else {
+		sb.WriteString(fmt.Sprintf("  cost=%.2f", n.TotalCost))
+	}
+	sb.WriteString(fmt.Sprintf(" rows=%s", formatPlanRows(n.EstimatedRows)))
+
+	if n.ActualRows >= 0 {
+		sb.WriteString(fmt.Sprintf("  actual rows=%s", formatPlanRows(n.ActualRows)))
+		if n.Loops > 1 {
+			sb.WriteString(fmt.Sprintf(" loops=%d", n.Loops))
+		}
+		if ratio := n.Misestimate(); ratio >= 10 {
+			sb.WriteString(fmt.Sprintf(" (ошибка оценки ×%.0f)", ratio))
+		}
+	}
+	if n.ActualTime >= 0 {
+		sb.WriteString(fmt.Sprintf(" time=%.3fms", n.ActualTime))
+	}
+	return sb.String()
+}
+
+// Misestimate во сколько раз оценка строк разошлась с фактом; 0, если факт неизвестен
+func (n *PlanNode) Misestimate() float64 {
+	if n.ActualRows < 0 {
+		return 0
+	}
+	estimated, actual := max(n.EstimatedRows, 1), max(n.ActualRows, 1)
+	if estimated > actual {
+		return estimated / actual
+	}
+	return actual / estimated
+}
+
+func formatPlanRows(rows float64) string {
+	return strconv.FormatFloat(rows, 'f', -1, 64)
+}
+
+var (
+	sqlStringLiteral     = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlLeadingComments   = regexp.MustCompile(`^\s*(?:(?:--[^\n]*\n|/\*(?s:.*?)\*/)\s*)*`)
+	postgresPlaceholders = regexp.MustCompile(`\$\d+`)
+)
+
+// explainAnalyzeStatements операторы, которые можно выполнить с ANALYZE. В PostgreSQL изменения откатываются
+// вместе с транзакцией. В MySQL изменения таблиц MyISAM и других нетранзакционных движков не откатываются,
+// а DDL неявно фиксирует транзакцию, поэтому допускается только чтение (WITH может начинать UPDATE/DELETE)
+var explainAnalyzeStatements = map[string][]string{
+	"postgresql": {"SELECT", "WITH", "VALUES", "TABLE", "INSERT", "UPDATE", "DELETE", "MERGE"},
+	"mysql":      {"SELECT", "VALUES", "TABLE"},
+}
+
+// prepareExplainQuery убирает завершающие ';' и проверяет, что запрос один и его можно выполнить с ANALYZE.
+// Несколько операторов недопустимы: без параметров lib/pq и клиенты в контейнере выполнят их все,
+// и, например, "SELECT 1; COMMIT; DELETE ..." зафиксирует изменения вопреки откату
+func prepareExplainQuery(dialect, query string, analyze bool) (string, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	if query == "" {
+		return "", fmt.Errorf("empty query")
+	}
+	// Обратная косая черта в строках - экранирование в MySQL и E'' PostgreSQL, но не в обычных
+	// строках PostgreSQL: ';' ищется при обоих прочтениях, чтобы ее нельзя было спрятать
+	for _, backslashEscapes := range []bool{false, true} {
+		if hasStatementSeparator(dialect, query, backslashEscapes) {
+			return "", fmt.Errorf("only a single statement can be explained")
+		}
+	}
+	if !analyze {
+		return query, nil
+	}
+
+	fields := strings.Fields(sqlLeadingComments.ReplaceAllString(query, ""))
+	if len(fields) > 0 {
+		keyword := strings.ToUpper(strings.TrimLeft(fields[0], "("))
+		if slices.Contains(explainAnalyzeStatements[dialect], keyword) {
+			return query, nil
+		}
+	}
+	return "", fmt.Errorf("EXPLAIN ANALYZE is only allowed for %s statements", strings.Join(explainAnalyzeStatements[dialect], "/"))
+}
+
+// postgresDollarTag открывающий тег строки в долларах: $$ или $tag$
+var postgresDollarTag = regexp.MustCompile(`^\$(?:[A-Za-z_\x{80}-\x{10FFFF}][A-Za-z0-9_\x{80}-\x{10FFFF}]*)?\$`)
+
+// hasStatementSeparator есть ли ';' вне строк, идентификаторов в кавычках и комментариев.
+// При сомнении разбор консервативен: вложенные комментарии PostgreSQL и /*! */ MySQL не пропускаются
+func hasStatementSeparator(dialect, query string, backslashEscapes bool) bool {
+	mysql := dialect == "mysql"
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == ';':
+			return true
+		case c == '\'' || c == '"' || (mysql && c == '`'):
+			i = skipQuoted(query, i, backslashEscapes && c != '`')
+		case c == '-' && strings.HasPrefix(query[i:], "--") && (!mysql || i+2 >= len(query) || query[i+2] <= ' '):
+			i = skipUntil(query, i, "\n")
+		case c == '#' && mysql:
+			i = skipUntil(query, i, "\n")
+		case c == '/' && strings.HasPrefix(query[i:], "/*") && !(mysql && strings.HasPrefix(query[i:], "/*!")):
+			i = skipUntil(query, i+2, "*/") + 1
+		case c == '$' && !mysql && (i == 0 || !isIdentByte(query[i-1])):
+			if tag := postgresDollarTag.FindString(query[i:]); tag != "" {
+				i = skipUntil(query, i+len(tag), tag) + len(tag) - 1
+			}
+		}
+	}
+	return false
+}
+
+// skipQuoted индекс закрывающей кавычки строки, начатой в start; удвоенная кавычка - экранирование
+func skipQuoted(query string, start int, backslashEscapes bool) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch {
+		case backslashEscapes && query[i] == '\\':
+			i++
+		case query[i] == quote && i+1 < len(query) && query[i+1] == quote:
+			i++
+		case query[i] == quote:
+			return i
+		}
+	}
+	return len(query)
+}
+
+// skipUntil индекс начала end после from; без end - конец запроса
+func skipUntil(query string, from int, end string) int {
+	if from >= len(query) {
+		return len(query)
+	}
+	if n := strings.Index(query[from:], end); n >= 0 {
+		return from + n
+	}
+	return len(query)
+}
+
+// isIdentByte символ идентификатора: после него '$' - часть имени, а не начало строки в долларах
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// hasPostgresPlaceholders запрос нормализован pg_stat_statements и содержит параметры $1, $2, ...
+func hasPostgresPlaceholders(query string) bool {
+	return postgresPlaceholders.MatchString(sqlStringLiteral.ReplaceAllString(query, ""))
+}
+
+// hasMySQLPlaceholders запрос нормализован performance_schema (digest) и содержит '?'
+func hasMySQLPlaceholders(query string) bool {
+	return strings.Contains(sqlStringLiteral.ReplaceAllString(query, ""), "?")
+}
+
+// postgresExplainStatement строит EXPLAIN для PostgreSQL. Запрос с параметрами объясняется
+// через GENERIC_PLAN (PostgreSQL 16+), выполнить его с ANALYZE нельзя
+func postgresExplainStatement(query string, analyze bool, serverVersion int) (string, bool, error) {
+	query, err := prepareExplainQuery("postgresql", query, analyze)
+	if err != nil {
+		return "", false, err
+	}
+
+	options := "FORMAT JSON"
+	generic := hasPostgresPlaceholders(query)
+	switch {
+	case generic && analyze:
+		return "", false, fmt.Errorf("query has parameters ($1, ...): substitute values to run EXPLAIN ANALYZE")
+	case generic && serverVersion < 160000:
+		return "", false, fmt.Errorf("query has parameters ($1, ...): substitute values or use PostgreSQL 16+ for a generic plan")
+	case generic:
+		options += ", GENERIC_PLAN"
+	case analyze:
+		options += ", ANALYZE, BUFFERS"
+	}
+
+	return fmt.Sprintf("EXPLAIN (%s) %s", options, query), generic, nil
+}
+
+// mysqlExplainAnalyzeJSON версия MySQL, с которой EXPLAIN ANALYZE поддерживает FORMAT=JSON
+const mysqlExplainAnalyzeJSON = 80300
+
+// mysqlExplainStatement строит EXPLAIN для MySQL/MariaDB по строке SELECT VERSION(). С ANALYZE в MariaDB
+// используется ANALYZE FORMAT=JSON, в MySQL - EXPLAIN ANALYZE FORMAT=JSON, который требует 8.3+ и
+// explain_json_format_version=2: setup - команда, которую нужно выполнить перед EXPLAIN, или пустая строка
+func mysqlExplainStatement(query string, analyze bool, version string) (statement, setup string, err error) {
+	query, err = prepareExplainQuery("mysql", query, analyze)
+	if err != nil {
+		return "", "", err
+	}
+	if hasMySQLPlaceholders(query) {
+		return "", "", fmt.Errorf("query is normalized (contains ?): substitute values to run EXPLAIN")
+	}
+
+	switch {
+	case analyze && strings.Contains(strings.ToLower(version), "mariadb"):
+		return "ANALYZE FORMAT=JSON " + query, "", nil
+	case analyze && mysqlVersionNum(version) < mysqlExplainAnalyzeJSON:
+		return "", "", fmt.Errorf("EXPLAIN ANALYZE with JSON output requires MySQL 8.3+, server is %s", version)
+	case analyze:
+		return "EXPLAIN ANALYZE FORMAT=JSON " + query, "SET SESSION explain_json_format_version = 2", nil
+	}
+	return "EXPLAIN FORMAT=JSON " + query, "", nil
+}
+
+// mysqlVersionNum переводит "8.0.35-log" в 80035; 0, если версию не разобрать
+func mysqlVersionNum(version string) int {
+	var major, minor, patch int
+	if n, _ := fmt.Sscanf(version, "%d.%d.%d", &major, &minor, &patch); n < 2 {
+		return 0
+	}
+	return major*10000 + minor*100 + patch
+}
+
+// postgresPlanJSON узел плана PostgreSQL; фактические значения есть только с ANALYZE
+type postgresPlanJSON struct {
+	NodeType        string             `json:"Node Type"`
+	RelationName    string             `json:"Relation Name"`
+	Alias           string             `json:"Alias"`
+	IndexName       string             `json:"Index Name"`
+	JoinType        string             `json:"Join Type"`
+	Strategy        string             `json:"Strategy"`
+	StartupCost     float64            `json:"Startup Cost"`
+	TotalCost       float64            `json:"Total Cost"`
+	PlanRows        float64            `json:"Plan Rows"`
+	ActualTotalTime *float64           `json:"Actual Total Time"`
+	ActualRows      *float64           `json:"Actual Rows"`
+	ActualLoops     *float64           `json:"Actual Loops"`
+	IndexCond       string             `json:"Index Cond"`
+	HashCond        string             `json:"Hash Cond"`
+	MergeCond       string             `json:"Merge Cond"`
+	JoinFilter      string             `json:"Join Filter"`
+	Filter          string             `json:"Filter"`
+	RecheckCond     string             `json:"Recheck Cond"`
+	RowsRemoved     *float64           `json:"Rows Removed by Filter"`
+	SortKey         []string           `json:"Sort Key"`
+	GroupKey        []string           `json:"Group Key"`
+	SubplanName     string             `json:"Subplan Name"`
+	Plans           []postgresPlanJSON `json:"Plans"`
+}
+
+// parsePostgresPlan разбирает вывод EXPLAIN (FORMAT JSON)
+func parsePostgresPlan(raw string) (*ExplainPlan, error) {
+	var result []struct {
+		Plan          postgresPlanJSON `json:"Plan"`
+		PlanningTime  *float64         `json:"Planning Time"`
+		ExecutionTime *float64         `json:"Execution Time"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("failed to parse plan: empty result")
+	}
+
+	plan := &ExplainPlan{
+		Root:          postgresPlanNode(result[0].Plan),
+		PlanningTime:  -1,
+		ExecutionTime: -1,
+		Raw:           raw,
+	}
+	if t := result[0].PlanningTime; t != nil {
+		plan.PlanningTime = time.Duration(*t * float64(time.Millisecond))
+	}
+	if t := result[0].ExecutionTime; t != nil {
+		plan.ExecutionTime = time.Duration(*t * float64(time.Millisecond))
+		plan.Analyzed = true
+	}
+	return plan, nil
+}
+
+func postgresPlanNode(p postgresPlanJSON) *PlanNode {
+	node := &PlanNode{
+		Operation:     p.NodeType,
+		Relation:      p.RelationName,
+		StartupCost:   p.StartupCost,
+		TotalCost:     p.TotalCost,
+		EstimatedRows: p.PlanRows,
+		ActualRows:    -1,
+		ActualTime:    -1,
+		SelfTime:      -1,
+	}
+	if p.JoinType != "" && p.JoinType != "Inner" {
+		node.Operation += " (" + p.JoinType + ")"
+	}
+	if p.Strategy != "" && p.Strategy != "Plain" {
+		node.Operation += " (" + p.Strategy + ")"
+	}
+	if p.Alias != "" && p.Alias != p.RelationName {
+		node.Relation += " " + p.Alias
+	}
+
+	if p.SubplanName != "" {
+		node.Details = append(node.Details, p.SubplanName)
+	}
+	if p.IndexName != "" {
+		node.Details = append(node.Details, "Index: "+p.IndexName)
+	}
+	for _, cond := range []struct{ name, value string }{
+		{"Index Cond", p.IndexCond},
+		{"Hash Cond", p.HashCond},
+		{"Merge Cond", p.MergeCond},
+		{"Recheck Cond", p.RecheckCond},
+		{"Join Filter", p.JoinFilter},
+		{"Filter", p.Filter},
+	} {
+		if cond.value != "" {
+			node.Details = append(node.Details, cond.name+": "+cond.value)
+		}
+	}
+	if p.RowsRemoved != nil && *p.RowsRemoved > 0 {
+		node.Details = append(node.Details, "Rows Removed by Filter: "+formatPlanRows(*p.RowsRemoved))
+	}
+	if len(p.SortKey) > 0 {
+		node.Details = append(node.Details, "Sort Key: "+strings.Join(p.SortKey, ", "))
+	}
+	if len(p.GroupKey) > 0 {
+		node.Details = append(node.Details, "Group Key: "+strings.Join(p.GroupKey, ", "))
+	}
+
+	// Фактические строки и время в PostgreSQL указаны за один цикл
+	if p.ActualRows != nil && p.ActualLoops != nil {
+		node.Loops = int64(*p.ActualLoops)
+		node.ActualRows = *p.ActualRows
+		if p.ActualTotalTime != nil {
+			node.ActualTime = *p.ActualTotalTime * *p.ActualLoops
+		}
+	}
+
+	node.SelfCost = node.TotalCost
+	for _, child := range p.Plans {
+		childNode := postgresPlanNode(child)
+		node.SelfCost -= childNode.TotalCost
+		node.Children = append(node.Children, childNode)
+	}
+	node.SelfCost = max(node.SelfCost, 0)
+	fillSelfTime(node)
+
+	return node
+}
+
+// fillSelfTime вычитает из времени узла время дочерних узлов
+func fillSelfTime(node *PlanNode) {
+	if node.ActualTime < 0 {
+		return
+	}
+	node.SelfTime = node.ActualTime
+	for _, child := range node.Children {
+		if child.ActualTime > 0 {
+			node.SelfTime -= child.ActualTime
+		}
+	}
+	node.SelfTime = max(node.SelfTime, 0)
+}
+
+// parseMySQLPlan разбирает EXPLAIN FORMAT=JSON: классический формат с query_block (MySQL 5.7+, MariaDB)
+// и формат версии 2 с operation/inputs, который MySQL 8.3+ выдает для EXPLAIN ANALYZE
+func parseMySQLPlan(raw string) (*ExplainPlan, error) {
+	var result map[string]any
+	if err := json.Unmarshal([]byte(extractJSON(raw)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	plan := &ExplainPlan{
+		PlanningTime:  -1,
+		ExecutionTime: -1,
+		Raw:           raw,
+	}
+	if block, ok := result["query_block"].(map[string]any); ok {
+		plan.Root = mysqlPlanNode("Query block", block)
+	} else if _, ok := result["operation"]; ok {
+		plan.Root = mysqlIteratorNode(result)
+	} else {
+		return nil, fmt.Errorf("failed to parse plan: unknown format")
+	}
+
+	for _, row := range plan.Rows(nil) {
+		if row.Node.ActualRows >= 0 {
+			plan.Analyzed = true
+		}
+	}
+	if plan.Analyzed && plan.Root.ActualTime >= 0 {
+		plan.ExecutionTime = time.Duration(plan.Root.ActualTime * float64(time.Millisecond))
+	}
+	return plan, nil
+}
+
+// mysqlPlanOperations вложенные элементы классического плана MySQL в порядке вывода
+var mysqlPlanOperations = []struct{ key, name string }{
+	{"union_result", "Union"},
+	{"query_specifications", "Select"},
+	{"ordering_operation", "Sort"},
+	{"grouping_operation", "Group"},
+	{"duplicates_removal", "Distinct"},
+	{"windowing", "Window"},
+	{"buffer_result", "Buffer"},
+	{"read_sorted_file", "Read sorted file"},
+	{"filesort", "Filesort"},
+	{"temporary_table", "Temporary table"},
+	{"query_block", "Query block"},
+	{"nested_loop", "Nested loop"},
+	{"table", "Table"},
+	{"materialized_from_subquery", "Materialize"},
+	{"attached_subqueries", "Subquery"},
+	{"optimized_away_subqueries", "Subquery"},
+	{"select_list_subqueries", "Subquery"},
+	{"order_by_subqueries", "Subquery"},
+	{"group_by_subqueries", "Subquery"},
+	{"having_subqueries", "Subquery"},
+	{"update_value_subqueries", "Subquery"},
+	{"subqueries", "Subquery"},
+}
+
+// mysqlAccessTypes названия типов доступа к таблице
+var mysqlAccessTypes = map[string]string{
+	"ALL":    "Full table scan",
+	"index":  "Full index scan",
+	"range":  "Index range scan",
+	"ref":    "Index lookup",
+	"eq_ref": "Unique index lookup",
+	"const":  "Constant lookup",
+	"system": "System table",
+}
+
+func mysqlPlanChildren(obj map[string]any) []*PlanNode {
+	var children []*PlanNode
+	for _, op := range mysqlPlanOperations {
+		switch value := obj[op.key].(type) {
+		case map[string]any:
+			children = append(children, mysqlPlanNode(op.name, value))
+		case []any:
+			// nested_loop - список {"table": ...}, соединяемых по порядку
+			if op.key == "nested_loop" {
+				loop := &PlanNode{Operation: op.name, ActualRows: -1, ActualTime: -1, SelfTime: -1}
+				for _, item := range value {
+					if itemObj, ok := item.(map[string]any); ok {
+						loop.Children = append(loop.Children, mysqlPlanChildren(itemObj)...)
+					}
+				}
+				for _, child := range loop.Children {
+					loop.TotalCost = max(loop.TotalCost, child.TotalCost)
+				}
+				children = append(children, loop)
+				continue
+			}
+			for _, item := range value {
+				if itemObj, ok := item.(map[string]any); ok {
+					children = append(children, mysqlPlanNode(op.name, itemObj))
+				}
+			}
+		}
+	}
+	return children
+}
+
+func mysqlPlanNode(name string, obj map[string]any) *PlanNode {
+	node := &PlanNode{Operation: name, ActualRows: -1, ActualTime: -1, SelfTime: -1}
+
+	costInfo, _ := obj["cost_info"].(map[string]any)
+	switch name {
+	case "Table":
+		accessType := jsonString(obj["access_type"])
+		node.Operation = accessType
+		if op, ok := mysqlAccessTypes[accessType]; ok {
+			node.Operation = op
+		}
+		node.Relation = jsonString(obj["table_name"])
+		node.SelfCost = jsonFloat(costInfo["read_cost"]) + jsonFloat(costInfo["eval_cost"])
+		node.TotalCost = jsonFloat(costInfo["prefix_cost"])
+		node.EstimatedRows = jsonFloat(obj["rows_examined_per_scan"])
+		if rows, ok := obj["rows"]; ok {
+			node.EstimatedRows = jsonFloat(rows) // MariaDB
+		}
+
+		if key := jsonString(obj["key"]); key != "" {
+			node.Details = append(node.Details, "Index: "+key)
+		}
+		if condition := jsonString(obj["attached_condition"]); condition != "" {
+			node.Details = append(node.Details, "Condition: "+condition)
+		}
+		if filtered := jsonString(obj["filtered"]); filtered != "" {
+			node.Details = append(node.Details, "Filtered: "+filtered+"%")
+		}
+	case "Sort", "Group":
+		node.SelfCost = jsonFloat(costInfo["sort_cost"])
+		if jsonBool(obj["using_filesort"]) {
+			node.Details = append(node.Details, "Using filesort")
+		}
+		if jsonBool(obj["using_temporary_table"]) {
+			node.Details = append(node.Details, "Using temporary table")
+		}
+	default:
+		node.TotalCost = jsonFloat(costInfo["query_cost"])
+	}
+
+	// MariaDB ANALYZE: r_rows и r_total_time_ms; cost без cost_info в новых версиях
+	if cost, ok := obj["cost"]; ok {
+		node.TotalCost = jsonFloat(cost)
+		if name == "Table" {
+			node.SelfCost = node.TotalCost
+		}
+	}
+	if rows, ok := obj["r_rows"]; ok {
+		node.ActualRows = jsonFloat(rows)
+	}
+	if t, ok := obj["r_total_time_ms"]; ok {
+		node.ActualTime = jsonFloat(t)
+	}
+	if loops, ok := obj["r_loops"]; ok {
+		node.Loops = int64(jsonFloat(loops))
+	}
+
+	node.Children = mysqlPlanChildren(obj)
+	for _, child := range node.Children {
+		node.TotalCost = max(node.TotalCost, child.TotalCost)
+	}
+	fillSelfTime(node)
+
+	return node
+}
+
+// mysqlIteratorNode узел плана формата версии 2; actual_last_row_ms указан за один цикл
+func mysqlIteratorNode(obj map[string]any) *PlanNode {
+	node := &PlanNode{
+		Operation:     jsonString(obj["operation"]), // уже содержит таблицу и индекс: "Table scan on t"
+		TotalCost:     jsonFloat(obj["estimated_total_cost"]),
+		EstimatedRows: jsonFloat(obj["estimated_rows"]),
+		ActualRows:    -1,
+		ActualTime:    -1,
+		SelfTime:      -1,
+	}
+	if index := jsonString(obj["index_name"]); index != "" {
+		node.Details = append(node.Details, "Index: "+index)
+	}
+	if condition := jsonString(obj["condition"]); condition != "" {
+		node.Details = append(node.Details, "Condition: "+condition)
+	}
+
+	if rows, ok := obj["actual_rows"]; ok {
+		node.ActualRows = jsonFloat(rows)
+		node.Loops = int64(jsonFloat(obj["actual_loops"]))
+		node.ActualTime = jsonFloat(obj["actual_last_row_ms"]) * float64(max(node.Loops, 1))
+	}
+
+	node.SelfCost = node.TotalCost
+	inputs, _ := obj["inputs"].([]any)
+	for _, input := range inputs {
+		if inputObj, ok := input.(map[string]any); ok {
+			child := mysqlIteratorNode(inputObj)
+			node.SelfCost -= child.TotalCost
+			node.Children = append(node.Children, child)
+		}
+	}
+	node.SelfCost = max(node.SelfCost, 0)
+	fillSelfTime(node)
+
+	return node
+}
+
+// extractJSON вырезает JSON из вывода клиента: между первой открывающей и последней закрывающей скобкой
+func extractJSON(output string) string {
+	start := strings.IndexAny(output, "[{")
+	end := strings.LastIndexAny(output, "]}")
+	if start < 0 || end < start {
+		return output
+	}
+	return output[start : end+1]
+}
+
+// jsonFloat число из JSON; MySQL отдает стоимости строками ("1.25")
+func jsonFloat(value any) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+func jsonString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func jsonBool(value any) bool {
+	b, _ := value.(bool)
+	return b
+}
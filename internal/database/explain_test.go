@@ -0,0 +1,93 @@
+package database
+
+import "testing"
+
+func TestPrepareExplainQueryRejectsMultipleStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		query   string
+	}{
+		{"commit and delete", "postgresql", "SELECT 1; COMMIT; DELETE FROM t"},
+		{"after trailing semicolon trim", "postgresql", "SELECT 1;DELETE FROM t;"},
+		{"after string", "postgresql", "SELECT 'a;b'; DELETE FROM t"},
+		{"backslash before quote", "postgresql", `SELECT 'a\'; DELETE FROM t; --'`},
+		{"escaped quote in mysql", "mysql", `SELECT 'a\'; DELETE FROM t; --'`},
+		{"identifier with dollar", "postgresql", "SELECT a$x$; DELETE FROM t; $x$"},
+		{"nested comment", "postgresql", "SELECT 1 /* /* */ ; DELETE FROM t */"},
+		{"mysql dash without space", "mysql", "SELECT 1--;DELETE FROM t"},
+		{"mysql executable comment", "mysql", "SELECT 1 /*!; DELETE FROM t */"},
+		{"after line comment", "mysql", "SELECT 1 -- comment\n; DELETE FROM t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, analyze := range []bool{false, true} {
+				if _, err := prepareExplainQuery(tt.dialect, tt.query, analyze); err == nil {
+					t.Errorf("prepareExplainQuery(%q, analyze=%v) accepted multiple statements", tt.query, analyze)
+				}
+			}
+		})
+	}
+}
+
+func TestPrepareExplainQueryAcceptsSingleStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		query   string
+		want    string
+	}{
+		{"trailing semicolons", "postgresql", "SELECT 1;; \n", "SELECT 1"},
+		{"semicolon in string", "postgresql", "SELECT ';' FROM t", "SELECT ';' FROM t"},
+		{"semicolon in identifier", "postgresql", `SELECT "a;b" FROM t`, `SELECT "a;b" FROM t`},
+		{"semicolon in dollar string", "postgresql", "SELECT $f$;$f$", "SELECT $f$;$f$"},
+		{"semicolon in comment", "postgresql", "SELECT 1 /* ; */ FROM t", "SELECT 1 /* ; */ FROM t"},
+		{"semicolon in backticks", "mysql", "SELECT `a;b` FROM t", "SELECT `a;b` FROM t"},
+		{"semicolon in hash comment", "mysql", "SELECT 1 # ;\nFROM t", "SELECT 1 # ;\nFROM t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := prepareExplainQuery(tt.dialect, tt.query, true)
+			if err != nil {
+				t.Fatalf("prepareExplainQuery(%q) error: %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("prepareExplainQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMySQLExplainStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		analyze   bool
+		version   string
+		statement string
+		setup     string
+		wantErr   bool
+	}{
+		{"plain explain on 8.0", "SELECT 1", false, "8.0.35", "EXPLAIN FORMAT=JSON SELECT 1", "", false},
+		{"analyze on 8.0", "SELECT 1", true, "8.0.35-log", "", "", true},
+		{"analyze on 8.3", "SELECT 1", true, "8.3.0", "EXPLAIN ANALYZE FORMAT=JSON SELECT 1", "SET SESSION explain_json_format_version = 2", false},
+		{"analyze on mariadb", "SELECT 1", true, "10.11.6-MariaDB", "ANALYZE FORMAT=JSON SELECT 1", "", false},
+		{"analyze dml", "DELETE FROM t", true, "8.4.0", "", "", true},
+		{"analyze with dml", "WITH c AS (SELECT 1) DELETE FROM t", true, "8.4.0", "", "", true},
+		{"explain dml", "DELETE FROM t", false, "8.0.35", "EXPLAIN FORMAT=JSON DELETE FROM t", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, setup, err := mysqlExplainStatement(tt.query, tt.analyze, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mysqlExplainStatement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if statement != tt.statement || setup != tt.setup {
+				t.Errorf("mysqlExplainStatement() = %q, %q; want %q, %q", statement, setup, tt.statement, tt.setup)
+			}
+		})
+	}
+}
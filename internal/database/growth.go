@@ -0,0 +1,101 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Окно и минимальный период для оценки роста таблиц
+const (
+	DefaultGrowthWindow = 15 * time.Minute
+	minGrowthPeriod     = 30 * time.Second // за меньший период рост по страницам слишком шумный
+)
+
+// TableGrowth скорость роста таблицы по последовательным снимкам
+type TableGrowth struct {
+	BytesPerHour float64
+	RowsPerHour  float64       // NaN, если количество строк неизвестно
+	Period       time.Duration // за какой период посчитана скорость
+}
+
+// growthSample размер и количество строк таблицы в момент снимка
+type growthSample struct {
+	at    time.Time
+	bytes int64
+	rows  int64
+}
+
+// GrowthTracker хранит снимки размеров таблиц за окно и считает скорость их роста
+type GrowthTracker struct {
+	window  time.Duration
+	samples map[string][]growthSample
+}
+
+// NewGrowthTracker создает трекер с окном window; скорость считается между самым старым и новым снимком окна
+func NewGrowthTracker(window time.Duration) *GrowthTracker {
+	return &GrowthTracker{
+		window:  window,
+		samples: make(map[string][]growthSample),
+	}
+}
+
+// Track добавляет снимок таблиц и заполняет их Growth; таблицы без размера и исчезнувшие таблицы забываются
+func (g *GrowthTracker) Track(tables []TableInfo, at time.Time) {
+	seen := make(map[string]bool, len(tables))
+	for i := range tables {
+		table := &tables[i]
+		if table.Sizes == nil {
+			continue
+		}
+
+		key := table.QualifiedName()
+		seen[key] = true
+
+		samples := append(g.samples[key], growthSample{at: at, bytes: table.Sizes.Total, rows: table.Rows})
+		for len(samples) > 1 && at.Sub(samples[0].at) > g.window {
+			samples = samples[1:]
+		}
+		g.samples[key] = samples
+
+		table.Growth = growthRate(samples[0], samples[len(samples)-1])
+	}
+
+	for key := range g.samples {
+		if !seen[key] {
+			delete(g.samples, key)
+		}
+	}
+}
+
+// growthRate скорость роста между двумя снимками; nil, если период слишком короткий
+func growthRate(first, last growthSample) *TableGrowth {
+	period := last.at.Sub(first.at)
+	if period < minGrowthPeriod {
+		return nil
+	}
+
+	hours := period.Hours()
+	growth := &TableGrowth{
+		BytesPerHour: float64(last.bytes-first.bytes) / hours,
+		RowsPerHour:  math.NaN(),
+		Period:       period,
+	}
+	if first.rows >= 0 && last.rows >= 0 {
+		growth.RowsPerHour = float64(last.rows-first.rows) / hours
+	}
+	return growth
+}
+
+// FormatGrowth форматирует рост в час: "+1.50 MB/ч", "-12.00 kB/ч"; nil - "n/a"
+func FormatGrowth(growth *TableGrowth) string {
+	if growth == nil {
+		return "n/a"
+	}
+
+	sign := "+"
+	if growth.BytesPerHour < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s/ч", sign, FormatSize(int64(math.Abs(growth.BytesPerHour))))
+}
@@ -0,0 +1,418 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Виды находок анализа индексов в порядке важности
+const (
+	IndexFindingInvalid     = "invalid"
+	IndexFindingDuplicate   = "duplicate"
+	IndexFindingOverlapping = "overlapping"
+	IndexFindingUnused      = "unused"
+	IndexFindingMissing     = "missing"
+)
+
+// Пороги эвристики "таблице нужен индекс"
+const (
+	missingIndexMinRows     = 10000 // маленькие таблицы дешевле читать целиком
+	missingIndexMinSeqRatio = 0.5   // доля последовательных чтений среди всех
+)
+
+// IndexInfo индекс таблицы со статистикой использования
+type IndexInfo struct {
+	Schema     string
+	Table      string
+	Name       string
+	Method     string // btree, hash, gin, FULLTEXT, ...
+	Columns    []string
+	Predicate  string // условие частичного индекса
+	SizeBytes  int64
+	Scans      int64 // -1, если статистика недоступна
+	Definition string
+	Unique     bool
+	Primary    bool
+	Valid      bool
+}
+
+// TableScanInfo соотношение последовательных и индексных чтений таблицы
+type TableScanInfo struct {
+	Schema   string
+	Table    string
+	SeqScans int64 // для MySQL - строки, прочитанные полным сканированием
+	IdxScans int64 // для MySQL - строки, прочитанные по индексам
+	Rows     int64
+}
+
+// IndexFinding проблема с индексом и SQL для ее исправления
+type IndexFinding struct {
+	Kind       string
+	Schema     string
+	Table      string
+	Index      string // пусто для находок missing
+	Detail     string
+	Suggestion string
+}
+
+// IndexReport индексы базы и найденные проблемы
+type IndexReport struct {
+	Indexes  []IndexInfo
+	Findings []IndexFinding
+}
+
+// buildIndexReport анализирует индексы; dialect - тип БД из DetectDBType для генерации SQL
+func buildIndexReport(dialect string, indexes []IndexInfo, scans []TableScanInfo) *IndexReport {
+	report := &IndexReport{Indexes: indexes}
+
+	// Индекс, уже отмеченный дубликатом, не помечается еще и неиспользуемым
+	flagged := make(map[string]bool)
+	key := func(index IndexInfo) string {
+		return index.Schema + "." + index.Table + "." + index.Name
+	}
+
+	for _, index := range indexes {
+		if !index.Valid {
+			report.Findings = append(report.Findings, IndexFinding{
+				Kind:       IndexFindingInvalid,
+				Schema:     index.Schema,
+				Table:      index.Table,
+				Index:      index.Name,
+				Detail:     "индекс невалиден (прерванный CREATE INDEX CONCURRENTLY) и не используется планировщиком",
+				Suggestion: reindexSQL(dialect, index),
+			})
+			flagged[key(index)] = true
+		}
+	}
+
+	byTable := make(map[string][]IndexInfo)
+	var tables []string
+	for _, index := range indexes {
+		table := index.Schema + "." + index.Table
+		if _, ok := byTable[table]; !ok {
+			tables = append(tables, table)
+		}
+		byTable[table] = append(byTable[table], index)
+	}
+	sort.Strings(tables)
+
+	// Сначала точные дубликаты, затем перекрытия: индекс-дубликат уникального не должен
+	// попасть в перекрытия более широкого индекса
+	for _, pass := range []string{IndexFindingDuplicate, IndexFindingOverlapping} {
+		for _, table := range tables {
+			list := byTable[table]
+			for i, a := range list {
+				for j, b := range list {
+					// Уникальные и первичные индексы держат ограничения, кандидаты на удаление - только обычные
+					if i == j || flagged[key(a)] || a.Unique || a.Primary || !a.Valid || !b.Valid ||
+						a.Method != b.Method || a.Predicate != b.Predicate {
+						continue
+					}
+
+					switch {
+					case pass == IndexFindingDuplicate && sameColumns(a.Columns, b.Columns):
+						// Из пары одинаковых обычных индексов оставляем первый по имени
+						if !b.Unique && !b.Primary && (a.Name < b.Name || flagged[key(b)]) {
+							continue
+						}
+						report.Findings = append(report.Findings, IndexFinding{
+							Kind:       IndexFindingDuplicate,
+							Schema:     a.Schema,
+							Table:      a.Table,
+							Index:      a.Name,
+							Detail:     fmt.Sprintf("дублирует %s (%s)", b.Name, strings.Join(b.Columns, ", ")),
+							Suggestion: dropIndexSQL(dialect, a),
+						})
+						flagged[key(a)] = true
+					case pass == IndexFindingOverlapping && isColumnPrefix(a.Columns, b.Columns):
+						report.Findings = append(report.Findings, IndexFinding{
+							Kind:       IndexFindingOverlapping,
+							Schema:     a.Schema,
+							Table:      a.Table,
+							Index:      a.Name,
+							Detail:     fmt.Sprintf("(%s) - префикс %s (%s)", strings.Join(a.Columns, ", "), b.Name, strings.Join(b.Columns, ", ")),
+							Suggestion: dropIndexSQL(dialect, a),
+						})
+						flagged[key(a)] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, index := range indexes {
+		// Уникальные и первичные индексы обеспечивают ограничения, даже если по ним не читают
+		if index.Scans != 0 || index.Unique || index.Primary || flagged[key(index)] {
+			continue
+		}
+		report.Findings = append(report.Findings, IndexFinding{
+			Kind:       IndexFindingUnused,
+			Schema:     index.Schema,
+			Table:      index.Table,
+			Index:      index.Name,
+			Detail:     fmt.Sprintf("ни одного чтения с момента сброса статистики, занимает %s", FormatSize(index.SizeBytes)),
+			Suggestion: dropIndexSQL(dialect, index),
+		})
+	}
+
+	for _, scan := range scans {
+		total := scan.SeqScans + scan.IdxScans
+		if scan.Rows < missingIndexMinRows || total == 0 {
+			continue
+		}
+		ratio := float64(scan.SeqScans) / float64(total)
+		if ratio <= missingIndexMinSeqRatio {
+			continue
+		}
+		report.Findings = append(report.Findings, IndexFinding{
+			Kind:   IndexFindingMissing,
+			Schema: scan.Schema,
+			Table:  scan.Table,
+			Detail: fmt.Sprintf("%.0f%% чтений последовательные (%d из %d), ~%d строк",
+				ratio*100, scan.SeqScans, total, scan.Rows),
+			Suggestion: createIndexSQL(dialect, scan),
+		})
+	}
+
+	return report
+}
+
+// sameColumns совпадают ли списки колонок с учетом порядка
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isColumnPrefix является ли a строгим префиксом b: такой индекс покрывается более широким
+func isColumnPrefix(a, b []string) bool {
+	if len(a) == 0 || len(a) >= len(b) {
+		return false
+	}
+	return sameColumns(a, b[:len(a)])
+}
+
+// quoteIdent берет идентификатор в кавычки диалекта всегда: даже имя из строчных букв может быть
+// зарезервированным словом (order, user, group)
+func quoteIdent(dialect, name string) string {
+	if dialect == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// qualifiedName имя объекта со схемой, если она есть
+func qualifiedName(dialect, schema, name string) string {
+	if schema == "" {
+		return quoteIdent(dialect, name)
+	}
+	return quoteIdent(dialect, schema) + "." + quoteIdent(dialect, name)
+}
+
+func dropIndexSQL(dialect string, index IndexInfo) string {
+	switch dialect {
+	case "mysql":
+		return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;",
+			qualifiedName(dialect, index.Schema, index.Table), quoteIdent(dialect, index.Name))
+	case "postgresql":
+		return fmt.Sprintf("DROP INDEX CONCURRENTLY %s;", qualifiedName(dialect, index.Schema, index.Name))
+	default:
+		return fmt.Sprintf("DROP INDEX %s;", qualifiedName(dialect, index.Schema, index.Name))
+	}
+}
+
+func reindexSQL(dialect string, index IndexInfo) string {
+	if dialect == "postgresql" {
+		return fmt.Sprintf("REINDEX INDEX CONCURRENTLY %s;", qualifiedName(dialect, index.Schema, index.Name))
+	}
+	return fmt.Sprintf("REINDEX %s;", qualifiedName(dialect, index.Schema, index.Name))
+}
+
+// createIndexSQL шаблон индекса: колонки нужно взять из условий частых запросов к таблице
+func createIndexSQL(dialect string, scan TableScanInfo) string {
+	table := qualifiedName(dialect, scan.Schema, scan.Table)
+	if dialect == "postgresql" {
+		return fmt.Sprintf("CREATE INDEX CONCURRENTLY ON %s (<колонки из WHERE/JOIN>);", table)
+	}
+	return fmt.Sprintf("CREATE INDEX idx_%s ON %s (<колонки из WHERE/JOIN>);", scan.Table, table)
+}
+
+// postgresIndexesQuery индексы пользовательских таблиц со сканами, ключевыми колонками (через перевод строки)
+// и условием. INCLUDE-колонки (indnkeyatts, PostgreSQL 11) в сравнении индексов не участвуют
+const postgresIndexesQuery = `
+	SELECT
+		s.schemaname,
+		s.relname,
+		s.indexrelname,
+		am.amname,
+		array_to_string(ARRAY(
+			SELECT pg_get_indexdef(s.indexrelid, k, true)
+			FROM generate_series(1, COALESCE((to_jsonb(i) ->> 'indnkeyatts')::int, i.indnatts)) AS k
+		), E'\n'),
+		COALESCE(pg_get_expr(i.indpred, i.indrelid), ''),
+		pg_relation_size(s.indexrelid),
+		s.idx_scan,
+		pg_get_indexdef(s.indexrelid),
+		i.indisunique,
+		i.indisprimary,
+		i.indisvalid
+	FROM pg_stat_user_indexes s
+	JOIN pg_index i ON i.indexrelid = s.indexrelid
+	JOIN pg_class c ON c.oid = s.indexrelid
+	JOIN pg_am am ON am.oid = c.relam
+	ORDER BY s.schemaname, s.relname, s.indexrelname
+`
+
+// postgresTableScansQuery последовательные и индексные сканирования таблиц
+const postgresTableScansQuery = `
+	SELECT
+		schemaname,
+		relname,
+		seq_scan,
+		COALESCE(idx_scan, 0),
+		n_live_tup
+	FROM pg_stat_user_tables
+	WHERE seq_scan > 0
+	ORDER BY seq_tup_read DESC
+`
+
+// postgresIndexes разбирает строки postgresIndexesQuery
+func postgresIndexes(rows [][]string) []IndexInfo {
+	var indexes []IndexInfo
+	for _, row := range rows {
+		indexes = append(indexes, IndexInfo{
+			Schema:     row[0],
+			Table:      row[1],
+			Name:       row[2],
+			Method:     row[3],
+			Columns:    strings.Split(row[4], "\n"),
+			Predicate:  row[5],
+			SizeBytes:  parseInt64(row[6]),
+			Scans:      parseInt64(row[7]),
+			Definition: row[8],
+			Unique:     parseBool(row[9]),
+			Primary:    parseBool(row[10]),
+			Valid:      parseBool(row[11]),
+		})
+	}
+	return indexes
+}
+
+// tableScans разбирает строки schema, table, seq, idx, rows
+func tableScans(rows [][]string) []TableScanInfo {
+	var scans []TableScanInfo
+	for _, row := range rows {
+		scans = append(scans, TableScanInfo{
+			Schema:   row[0],
+			Table:    row[1],
+			SeqScans: parseInt64(row[2]),
+			IdxScans: parseInt64(row[3]),
+			Rows:     parseInt64(row[4]),
+		})
+	}
+	return scans
+}
+
+// mysqlIndexesQuery индексы текущей базы. Чтения берутся из того же источника, что и
+// sys.schema_unused_indexes; без performance_schema статистика неизвестна (-1)
+const mysqlIndexesQuery = `
+	SELECT
+		s.table_schema,
+		s.table_name,
+		s.index_name,
+		MAX(s.index_type),
+		GROUP_CONCAT(COALESCE(s.column_name, '<expr>') ORDER BY s.seq_in_index SEPARATOR ','),
+		COALESCE(MAX(st.stat_value) * @@innodb_page_size, 0),
+		COALESCE(MAX(u.count_read), -1),
+		MIN(s.non_unique) = 0,
+		s.index_name = 'PRIMARY'
+	FROM information_schema.statistics s
+	LEFT JOIN mysql.innodb_index_stats st
+		ON st.database_name = s.table_schema AND st.table_name = s.table_name
+		AND st.index_name = s.index_name AND st.stat_name = 'size'
+	LEFT JOIN performance_schema.table_io_waits_summary_by_index_usage u
+		ON u.object_schema = s.table_schema AND u.object_name = s.table_name AND u.index_name = s.index_name
+	WHERE s.table_schema = DATABASE()
+	GROUP BY s.table_schema, s.table_name, s.index_name
+	ORDER BY s.table_name, s.index_name
+`
+
+// mysqlIndexesFallbackQuery индексы без mysql.innodb_index_stats и performance_schema (нет прав)
+const mysqlIndexesFallbackQuery = `
+	SELECT
+		s.table_schema,
+		s.table_name,
+		s.index_name,
+		MAX(s.index_type),
+		GROUP_CONCAT(COALESCE(s.column_name, '<expr>') ORDER BY s.seq_in_index SEPARATOR ','),
+		0,
+		-1,
+		MIN(s.non_unique) = 0,
+		s.index_name = 'PRIMARY'
+	FROM information_schema.statistics s
+	WHERE s.table_schema = DATABASE()
+	GROUP BY s.table_schema, s.table_name, s.index_name
+	ORDER BY s.table_name, s.index_name
+`
+
+// mysqlTableScansQuery строки, прочитанные полным сканированием и по индексам (как sys.schema_tables_with_full_table_scans)
+const mysqlTableScansQuery = `
+	SELECT
+		u.object_schema,
+		u.object_name,
+		SUM(IF(u.index_name IS NULL, u.count_read, 0)),
+		SUM(IF(u.index_name IS NOT NULL, u.count_read, 0)),
+		COALESCE(MAX(t.table_rows), 0)
+	FROM performance_schema.table_io_waits_summary_by_index_usage u
+	JOIN information_schema.tables t ON t.table_schema = u.object_schema AND t.table_name = u.object_name
+	WHERE u.object_schema = DATABASE()
+	GROUP BY u.object_schema, u.object_name
+`
+
+// mysqlIndexes разбирает строки mysqlIndexesQuery; определение собирается из колонок
+func mysqlIndexes(rows [][]string) []IndexInfo {
+	var indexes []IndexInfo
+	for _, row := range rows {
+		index := IndexInfo{
+			Schema:    row[0],
+			Table:     row[1],
+			Name:      row[2],
+			Method:    row[3],
+			Columns:   strings.Split(row[4], ","),
+			SizeBytes: parseInt64(row[5]),
+			Scans:     parseInt64(row[6]),
+			Unique:    parseBool(row[7]),
+			Primary:   parseBool(row[8]),
+			Valid:     true,
+		}
+
+		quoted := make([]string, len(index.Columns))
+		for i, column := range index.Columns {
+			quoted[i] = quoteIdent("mysql", column)
+		}
+		switch {
+		case index.Primary:
+			index.Definition = fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(quoted, ", "))
+		case index.Method == "FULLTEXT" || index.Method == "SPATIAL":
+			index.Definition = fmt.Sprintf("CREATE %s INDEX %s ON %s (%s)", index.Method,
+				quoteIdent("mysql", index.Name), quoteIdent("mysql", index.Table), strings.Join(quoted, ", "))
+		default:
+			kind := "INDEX"
+			if index.Unique {
+				kind = "UNIQUE INDEX"
+			}
+			index.Definition = fmt.Sprintf("CREATE %s %s ON %s (%s) USING %s", kind,
+				quoteIdent("mysql", index.Name), quoteIdent("mysql", index.Table), strings.Join(quoted, ", "), index.Method)
+		}
+
+		indexes = append(indexes, index)
+	}
+	return indexes
+}
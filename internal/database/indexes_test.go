@@ -0,0 +1,40 @@
+package database
+
+import "testing"
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		dialect string
+		name    string
+		want    string
+	}{
+		{"postgresql", "orders", `"orders"`},
+		{"postgresql", "order", `"order"`},
+		{"postgresql", "Users", `"Users"`},
+		{"postgresql", `we"ird`, `"we""ird"`},
+		{"sqlite", "group", `"group"`},
+		{"mysql", "order", "`order`"},
+		{"mysql", "we`ird", "`we``ird`"},
+		{"mysql", `say "hi"`, "`say \"hi\"`"},
+	}
+
+	for _, tt := range tests {
+		if got := quoteIdent(tt.dialect, tt.name); got != tt.want {
+			t.Errorf("quoteIdent(%q, %q) = %s, want %s", tt.dialect, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIndexSQLQuotesReservedWords(t *testing.T) {
+	index := IndexInfo{Schema: "public", Table: "order", Name: "user"}
+
+	if got, want := dropIndexSQL("postgresql", index), `DROP INDEX CONCURRENTLY "public"."user";`; got != want {
+		t.Errorf("dropIndexSQL(postgresql) = %s, want %s", got, want)
+	}
+	if got, want := dropIndexSQL("mysql", index), "ALTER TABLE `public`.`order` DROP INDEX `user`;"; got != want {
+		t.Errorf("dropIndexSQL(mysql) = %s, want %s", got, want)
+	}
+	if got, want := countRowsQuery("sqlite", TableInfo{Name: "order"}), `SELECT COUNT(*) FROM "order"`; got != want {
+		t.Errorf("countRowsQuery(sqlite) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package database
+
+import "sort"
+
+// LockNode узел дерева блокировок: сессия и сессии, которые она блокирует
+type LockNode struct {
+	Lock     LockInfo
+	Children []*LockNode
+}
+
+// BuildBlockingTree строит дерево "кто кого блокирует".
+// Корни дерева - сессии, которые блокируют других, но сами не ждут.
+func BuildBlockingTree(locks []LockInfo) []*LockNode {
+	nodes := make(map[string]*LockNode, len(locks))
+	for _, lock := range locks {
+		nodes[lock.ID] = &LockNode{Lock: lock}
+	}
+
+	for _, lock := range locks {
+		for _, blockerID := range lock.BlockedBy {
+			blocker, ok := nodes[blockerID]
+			if !ok {
+				// Блокирующая сессия не попала в выборку - добавляем заглушку
+				blocker = &LockNode{Lock: LockInfo{ID: blockerID}}
+				nodes[blockerID] = blocker
+			}
+			blocker.Children = append(blocker.Children, nodes[lock.ID])
+		}
+	}
+
+	var roots []*LockNode
+	for _, node := range nodes {
+		if len(node.Lock.BlockedBy) == 0 && len(node.Children) > 0 {
+			roots = append(roots, node)
+		}
+	}
+
+	// Участники взаимных блокировок (deadlock) недостижимы из корней - добавляем их отдельно
+	reachable := make(map[string]bool)
+	WalkBlockingTree(roots, func(node *LockNode, depth int) {
+		reachable[node.Lock.ID] = true
+	})
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		node := nodes[id]
+		if reachable[id] || len(node.Children) == 0 {
+			continue
+		}
+		roots = append(roots, node)
+		WalkBlockingTree([]*LockNode{node}, func(node *LockNode, depth int) {
+			reachable[node.Lock.ID] = true
+		})
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Lock.ID < roots[j].Lock.ID
+	})
+	for _, node := range nodes {
+		sort.Slice(node.Children, func(i, j int) bool {
+			return node.Children[i].Lock.ID < node.Children[j].Lock.ID
+		})
+	}
+
+	return roots
+}
+
+// WalkBlockingTree обходит дерево в глубину, вызывая fn для каждого узла с его глубиной.
+// Каждая сессия посещается один раз, поэтому циклы не приводят к зацикливанию.
+func WalkBlockingTree(roots []*LockNode, fn func(node *LockNode, depth int)) {
+	visited := make(map[string]bool)
+
+	var walk func(node *LockNode, depth int)
+	walk = func(node *LockNode, depth int) {
+		if visited[node.Lock.ID] {
+			return
+		}
+		visited[node.Lock.ID] = true
+
+		fn(node, depth)
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+
+	for _, root := range roots {
+		walk(root, 0)
+	}
+}
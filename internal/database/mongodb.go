@@ -0,0 +1,521 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// mongoTimeout ограничение на одну команду к MongoDB
+const mongoTimeout = 10 * time.Second
+
+// mongoOpcounters типы операций из serverStatus.opcounters в порядке показа
+var mongoOpcounters = []string{"insert", "query", "update", "delete", "getmore", "command"}
+
+// mongoSystemDatabases служебные БД, которые не показываются без явного выбора
+var mongoSystemDatabases = map[string]bool{"admin": true, "config": true, "local": true}
+
+// MongoDBMonitor мониторинг MongoDB через serverStatus, collStats и currentOp
+type MongoDBMonitor struct {
+	client   *mongo.Client
+	database string // БД из connection string; пустая - все пользовательские БД
+	counters counterTracker
+
+	prevOps   map[string]int64 // opcounters прошлого тика для скоростей по типам
+	prevOpsAt time.Time
+
+	slowQuerySettings
+	schemaSettings
+}
+
+// mongoServerStatus нужные поля serverStatus
+type mongoServerStatus struct {
+	Version     string  `bson:"version"`
+	Uptime      float64 `bson:"uptime"`
+	Connections struct {
+		Current   int64 `bson:"current"`
+		Available int64 `bson:"available"`
+	} `bson:"connections"`
+	Opcounters  map[string]int64 `bson:"opcounters"`
+	OpLatencies map[string]struct {
+		Latency int64 `bson:"latency"` // микросекунды
+		Ops     int64 `bson:"ops"`
+	} `bson:"opLatencies"`
+	Asserts struct {
+		Regular int64 `bson:"regular"`
+		User    int64 `bson:"user"`
+	} `bson:"asserts"`
+	Mem struct {
+		Resident int64 `bson:"resident"` // MB
+	} `bson:"mem"`
+	StorageEngine struct {
+		Name string `bson:"name"`
+	} `bson:"storageEngine"`
+	Repl struct {
+		SetName string `bson:"setName"`
+	} `bson:"repl"`
+}
+
+// mongoOperation элемент currentOp.inprog
+type mongoOperation struct {
+	OpID             interface{} `bson:"opid"` // число или "shard:opid" в шардированном кластере
+	Type             string      `bson:"type"`
+	Active           bool        `bson:"active"`
+	Client           string      `bson:"client"`
+	NS               string      `bson:"ns"`
+	Op               string      `bson:"op"`
+	MicrosecsRunning int64       `bson:"microsecs_running"`
+	WaitingForLock   bool        `bson:"waitingForLock"`
+	Command          bson.Raw    `bson:"command"`
+	EffectiveUsers   []struct {
+		User string `bson:"user"`
+	} `bson:"effectiveUsers"`
+}
+
+// mongoProfileEntry запись system.profile
+type mongoProfileEntry struct {
+	Op      string    `bson:"op"`
+	NS      string    `bson:"ns"`
+	Millis  int64     `bson:"millis"`
+	TS      time.Time `bson:"ts"`
+	User    string    `bson:"user"`
+	Command bson.Raw  `bson:"command"`
+}
+
+// NewMongoDBMonitor создает новый монитор для MongoDB
+func NewMongoDBMonitor() *MongoDBMonitor {
+	return &MongoDBMonitor{}
+}
+
+// Connect подключается к MongoDB по mongodb:// или mongodb+srv:// URI
+func (m *MongoDBMonitor) Connect(connectionString string) error {
+	cs, err := connstring.ParseAndValidate(connectionString)
+	if err != nil {
+		return fmt.Errorf("failed to parse MongoDB URI: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(connectionString))
+	if err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	m.client = client
+	m.database = cs.Database
+	return nil
+}
+
+// GetStats получает статистику MongoDB
+func (m *MongoDBMonitor) GetStats() (*DBStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	stats := &DBStats{
+		LastUpdate: time.Now(),
+	}
+
+	var status mongoServerStatus
+	err := m.client.Database("admin").RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server status: %w", err)
+	}
+
+	stats.ActiveConnections = int(status.Connections.Current)
+	stats.MaxConnections = int(status.Connections.Current + status.Connections.Available)
+
+	// Скорость по opcounters, задержка по opLatencies (микросекунды)
+	sample := counterSample{
+		at:     time.Now(),
+		errors: float64(status.Asserts.Regular + status.Asserts.User),
+	}
+	for _, name := range mongoOpcounters {
+		sample.queries += float64(status.Opcounters[name])
+	}
+	for _, latency := range status.OpLatencies {
+		sample.busyTime += time.Duration(latency.Latency) * time.Microsecond
+	}
+	m.counters.update(sample, stats)
+
+	size, err := m.databaseSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.DatabaseSize = FormatSize(size)
+
+	stats.Properties = append(stats.Properties,
+		DBProperty{Name: "Версия", Value: status.Version},
+		DBProperty{Name: "Аптайм", Value: (time.Duration(status.Uptime) * time.Second).String()},
+		DBProperty{Name: "Движок хранения", Value: status.StorageEngine.Name},
+		DBProperty{Name: "Резидентная память", Value: fmt.Sprintf("%d MB", status.Mem.Resident)},
+		DBProperty{Name: "Операции/сек", Value: m.opRates(status.Opcounters, sample.at)},
+	)
+	if status.Repl.SetName != "" {
+		stats.Properties = append(stats.Properties, DBProperty{Name: "Replica set", Value: status.Repl.SetName})
+	}
+
+	tables, err := m.GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tables: %w", err)
+	}
+	stats.Tables = tables
+	stats.TableCount = len(tables)
+
+	slowQueries, err := m.GetSlowQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slow queries: %w", err)
+	}
+	stats.SlowQueries = slowQueries
+
+	return stats, nil
+}
+
+// opRates скорости opcounters по типам за последний интервал
+func (m *MongoDBMonitor) opRates(opcounters map[string]int64, at time.Time) string {
+	prev, prevAt := m.prevOps, m.prevOpsAt
+	m.prevOps, m.prevOpsAt = opcounters, at
+
+	elapsed := at.Sub(prevAt).Seconds()
+	if prev == nil || elapsed <= 0 {
+		return "n/a"
+	}
+
+	var parts []string
+	for _, name := range mongoOpcounters {
+		delta := opcounters[name] - prev[name]
+		if delta < 0 {
+			// Сервер перезапущен
+			return "n/a"
+		}
+		parts = append(parts, fmt.Sprintf("%s %.1f", name, float64(delta)/elapsed))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// databaseSize размер выбранной БД (данные и индексы на диске) или всех БД сервера
+func (m *MongoDBMonitor) databaseSize(ctx context.Context) (int64, error) {
+	if m.database == "" {
+		result, err := m.client.ListDatabases(ctx, bson.D{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list databases: %w", err)
+		}
+		return result.TotalSize, nil
+	}
+
+	var dbStats struct {
+		StorageSize int64 `bson:"storageSize"`
+		IndexSize   int64 `bson:"indexSize"`
+	}
+	err := m.client.Database(m.database).RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&dbStats)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get database size: %w", err)
+	}
+	return dbStats.StorageSize + dbStats.IndexSize, nil
+}
+
+// databases БД, коллекции которых показываются как таблицы
+func (m *MongoDBMonitor) databases(ctx context.Context) ([]string, error) {
+	if m.database != "" {
+		return []string{m.database}, nil
+	}
+
+	names, err := m.client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	var databases []string
+	for _, name := range names {
+		if !mongoSystemDatabases[name] {
+			databases = append(databases, name)
+		}
+	}
+	return databases, nil
+}
+
+// GetTables получает коллекции с количеством документов, размером на диске и числом индексов
+func (m *MongoDBMonitor) GetTables() ([]TableInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	databases, err := m.databases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []TableInfo
+	for _, dbName := range databases {
+		db := m.client.Database(dbName)
+		names, err := db.ListCollectionNames(ctx, bson.D{{Key: "type", Value: "collection"}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list collections: %w", err)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			table := TableInfo{Name: name, Size: "n/a", Rows: -1, LastUpdate: time.Now()}
+			if m.database == "" {
+				table.Schema = dbName
+			}
+
+			var collStats struct {
+				Count          int64 `bson:"count"`
+				StorageSize    int64 `bson:"storageSize"`
+				TotalIndexSize int64 `bson:"totalIndexSize"`
+				NIndexes       int   `bson:"nindexes"`
+			}
+			err := db.RunCommand(ctx, bson.D{{Key: "collStats", Value: name}}).Decode(&collStats)
+			if err == nil {
+				table.Sizes = &TableSizes{
+					Total: collStats.StorageSize + collStats.TotalIndexSize,
+					Heap:  collStats.StorageSize,
+					Index: collStats.TotalIndexSize,
+					Toast: -1,
+				}
+				table.Size = FormatSize(table.Sizes.Total)
+				table.Rows = collStats.Count
+				table.Indexes = collStats.NIndexes
+			}
+
+			tables = append(tables, table)
+		}
+	}
+
+	return tables, nil
+}
+
+// CountRows точно считает документы коллекции: count из collStats берется из метаданных
+// и может расходиться с реальным после аварийного завершения
+func (m *MongoDBMonitor) CountRows(table TableInfo) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	dbName := table.Schema
+	if dbName == "" {
+		dbName = m.database
+	}
+	count, err := m.client.Database(dbName).Collection(table.Name).CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return count, nil
+}
+
+// currentOp выполняет currentOp с фильтром
+func (m *MongoDBMonitor) currentOp(ctx context.Context, filter bson.D) ([]mongoOperation, error) {
+	command := append(bson.D{{Key: "currentOp", Value: 1}}, filter...)
+
+	var result struct {
+		InProg []mongoOperation `bson:"inprog"`
+	}
+	if err := m.client.Database("admin").RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.InProg, nil
+}
+
+// GetSlowQueries получает выполняющиеся дольше секунды операции из currentOp
+// и, если для БД включен профайлер, последние медленные записи system.profile
+func (m *MongoDBMonitor) GetSlowQueries() ([]SlowQuery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	opts := m.SlowQueryOptions()
+	ops, err := m.currentOp(ctx, bson.D{
+		{Key: "active", Value: true},
+		{Key: "microsecs_running", Value: bson.D{{Key: "$gte", Value: opts.Threshold.Microseconds()}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query current operations: %w", err)
+	}
+
+	slowQueries := []SlowQuery{}
+	for _, op := range ops {
+		slowQueries = append(slowQueries, SlowQuery{
+			Query:     truncateQuery(op.describe()),
+			Duration:  time.Duration(op.MicrosecsRunning) * time.Microsecond,
+			Timestamp: time.Now(),
+			User:      op.user(),
+		})
+	}
+
+	if m.database == "" {
+		return slowQueries, nil
+	}
+
+	// Профайлер выключен - коллекции нет или она пуста, это не ошибка
+	cursor, err := m.client.Database(m.database).Collection("system.profile").Find(ctx,
+		bson.D{{Key: "millis", Value: bson.D{{Key: "$gte", Value: opts.Threshold.Milliseconds()}}}},
+		options.Find().SetSort(bson.D{{Key: "ts", Value: -1}}).SetLimit(int64(opts.Limit)))
+	if err != nil {
+		return slowQueries, nil
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry mongoProfileEntry
+		if err := cursor.Decode(&entry); err != nil {
+			continue
+		}
+		slowQueries = append(slowQueries, SlowQuery{
+			Query:     truncateQuery(fmt.Sprintf("%s %s %s", entry.Op, entry.NS, commandText(entry.Command))),
+			Duration:  time.Duration(entry.Millis) * time.Millisecond,
+			Timestamp: entry.TS,
+			User:      entry.User,
+		})
+	}
+
+	return slowQueries, nil
+}
+
+// ResetSlowQueries профайлер MongoDB пишет в capped-коллекцию, очистить ее без остановки профилирования нельзя
+func (m *MongoDBMonitor) ResetSlowQueries() error {
+	return fmt.Errorf("resetting slow queries is not supported for MongoDB")
+}
+
+// GetConnections получает клиентские операции из currentOp, включая простаивающие
+func (m *MongoDBMonitor) GetConnections() ([]ConnectionInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	ops, err := m.currentOp(ctx, bson.D{{Key: "$all", Value: true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connections: %w", err)
+	}
+
+	var connections []ConnectionInfo
+	for _, op := range ops {
+		// Внутренние потоки сервера без клиента и сам запрос currentOp не показываем
+		if op.Client == "" || op.OpID == nil || op.isCurrentOp() {
+			continue
+		}
+
+		conn := ConnectionInfo{
+			ID:         fmt.Sprint(op.OpID),
+			User:       op.user(),
+			ClientAddr: op.Client,
+			State:      "idle",
+			Query:      op.describe(),
+			QueryAge:   time.Duration(op.MicrosecsRunning) * time.Microsecond,
+		}
+		if op.Active {
+			conn.State = "active"
+		}
+		if op.WaitingForLock {
+			conn.WaitEvent = "lock"
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// GetLocks MongoDB не сообщает, какая операция держит блокировку
+func (m *MongoDBMonitor) GetLocks() ([]LockInfo, error) {
+	return []LockInfo{}, nil
+}
+
+// GetReplication репликация MongoDB пока не отслеживается
+func (m *MongoDBMonitor) GetReplication() (*ReplicationStatus, error) {
+	return &ReplicationStatus{}, nil
+}
+
+// GetIndexes анализ индексов MongoDB пока не поддерживается
+func (m *MongoDBMonitor) GetIndexes() (*IndexReport, error) {
+	return &IndexReport{}, nil
+}
+
+// ListSchemas выбор схемы для MongoDB не поддерживается
+func (m *MongoDBMonitor) ListSchemas() ([]string, error) {
+	return nil, fmt.Errorf("schema selection is not supported for MongoDB")
+}
+
+// Explain планы запросов показываются только для PostgreSQL и MySQL
+func (m *MongoDBMonitor) Explain(query string, analyze bool) (*ExplainPlan, error) {
+	return nil, fmt.Errorf("EXPLAIN is not supported for MongoDB")
+}
+
+// KillConnection прерывает операцию через killOp; ID - opid из currentOp
+func (m *MongoDBMonitor) KillConnection(connectionID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	// В шардированном кластере opid имеет вид "shard:opid" и передается строкой
+	var op interface{} = connectionID
+	if id, err := strconv.ParseInt(connectionID, 10, 32); err == nil {
+		op = int32(id)
+	}
+
+	err := m.client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "killOp", Value: 1},
+		{Key: "op", Value: op},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to kill operation %s: %w", connectionID, err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с MongoDB
+func (m *MongoDBMonitor) Close() error {
+	if m.client != nil {
+		return m.client.Disconnect(context.Background())
+	}
+	return nil
+}
+
+// describe краткое описание операции: тип, коллекция и команда
+func (op mongoOperation) describe() string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", op.Op, op.NS, commandText(op.Command)))
+}
+
+// user первый аутентифицированный пользователь операции
+func (op mongoOperation) user() string {
+	if len(op.EffectiveUsers) > 0 {
+		return op.EffectiveUsers[0].User
+	}
+	return ""
+}
+
+// isCurrentOp операция - это собственный запрос монитора к currentOp
+func (op mongoOperation) isCurrentOp() bool {
+	if len(op.Command) == 0 {
+		return false
+	}
+	_, err := op.Command.LookupErr("currentOp")
+	return err == nil
+}
+
+// commandText команда в виде relaxed Extended JSON
+func commandText(command bson.Raw) string {
+	if len(command) == 0 {
+		return ""
+	}
+	text, err := bson.MarshalExtJSON(command, false, false)
+	if err != nil {
+		return command.String()
+	}
+	return string(text)
+}
+
+// truncateQuery обрезает длинный текст запроса
+func truncateQuery(query string) string {
+	if len(query) > 100 {
+		return query[:97] + "..."
+	}
+	return query
+}
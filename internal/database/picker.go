@@ -0,0 +1,87 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pickerModel экран выбора контейнера с БД
+type pickerModel struct {
+	targets  []DockerTarget
+	cursor   int
+	selected *DockerTarget
+}
+
+// PickContainer показывает список найденных контейнеров и возвращает выбранный; nil, если выбор отменен
+func PickContainer(targets []DockerTarget) (*DockerTarget, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no running database containers found")
+	}
+
+	result, err := tea.NewProgram(pickerModel{targets: targets}).Run()
+	if err != nil {
+		return nil, err
+	}
+	return result.(pickerModel).selected, nil
+}
+
+func (m pickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.targets)-1 {
+				m.cursor++
+			}
+		case "enter":
+			m.selected = &m.targets[m.cursor]
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m pickerModel) View() string {
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FF6B6B")).
+		Bold(true).
+		Render("🐳 Контейнеры с базами данных")
+	sb.WriteString(title + "\n\n")
+
+	selected := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#000000")).
+		Background(lipgloss.Color("#FF6B6B"))
+	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+
+	for i, t := range m.targets {
+		line := fmt.Sprintf("%-24s %-10s %-28s %s@%s", truncate(t.Name, 24), t.Engine, truncate(t.Image, 28), t.User, t.Database)
+		if t.Password == "" {
+			line += muted.Render("  (без пароля)")
+		}
+		if i == m.cursor {
+			line = selected.Render(line)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	help := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#626262")).
+		Render("↑/↓: выбор | Enter: подключиться | q: выход")
+	sb.WriteString("\n" + help)
+
+	return sb.String()
+}
@@ -8,6 +8,45 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// postgresLocksQuery выбирает ожидающие и блокирующие сессии.
+// Для ожидающих сессий подтягивается первая неполученная блокировка из pg_locks.
+const postgresLocksQuery = `
+	SELECT 
+		a.pid,
+		COALESCE(a.usename, ''),
+		COALESCE(a.state, ''),
+		COALESCE(array_to_string(pg_blocking_pids(a.pid), ','), ''),
+		COALESCE(l.locktype, ''),
+		COALESCE(l.mode, ''),
+		COALESCE(l.relation::regclass::text, ''),
+		COALESCE(EXTRACT(EPOCH FROM now() - a.state_change), 0)::bigint,
+		COALESCE(translate(a.query, E'\n\r\t', '   '), '')
+	FROM pg_stat_activity a
+	LEFT JOIN LATERAL (
+		SELECT locktype, mode, relation
+		FROM pg_locks 
+		WHERE pid = a.pid AND NOT granted
+		LIMIT 1
+	) l ON true
+	WHERE cardinality(pg_blocking_pids(a.pid)) > 0
+	   OR a.pid IN (
+		SELECT unnest(pg_blocking_pids(pid)) FROM pg_stat_activity
+	   )
+	ORDER BY a.pid
+`
+
+// postgresCountersQuery читает накопительные счетчики pg_stat_database.
+// active_time появился в PostgreSQL 14, поэтому читается через to_jsonb без ошибки на старых версиях.
+// Ошибки - только xact_rollback: транзакция жертвы дедлока уже посчитана в нем
+const postgresCountersQuery = `
+	SELECT 
+		xact_commit + xact_rollback,
+		xact_rollback,
+		COALESCE((to_jsonb(d) ->> 'active_time')::float8, 0)
+	FROM pg_stat_database d
+	WHERE datname = current_database()
+`
+
 // Connect подключается к PostgreSQL
 func (p *PostgreSQLMonitor) Connect(connectionString string) error {
 	db, err := sql.Open("postgres", connectionString)
@@ -50,6 +89,17 @@ func (p *PostgreSQLMonitor) GetStats() (*DBStats, error) {
 	}
 	stats.MaxConnections = maxConnections
 
+	// Счетчики транзакций для расчета скорости и задержки
+	var sample counterSample
+	var activeTimeMs float64
+	err = p.db.QueryRow(postgresCountersQuery).Scan(&sample.queries, &sample.errors, &activeTimeMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction counters: %w", err)
+	}
+	sample.at = time.Now()
+	sample.busyTime = time.Duration(activeTimeMs * float64(time.Millisecond))
+	p.counters.update(sample, stats)
+
 	// Размер базы данных
 	var dbSize string
 	err = p.db.QueryRow(`
@@ -60,24 +110,13 @@ func (p *PostgreSQLMonitor) GetStats() (*DBStats, error) {
 	}
 	stats.DatabaseSize = dbSize
 
-	// Количество таблиц
-	var tableCount int
-	err = p.db.QueryRow(`
-		SELECT count(*) 
-		FROM information_schema.tables 
-		WHERE table_schema = 'public'
-	`).Scan(&tableCount)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get table count: %w", err)
-	}
-	stats.TableCount = tableCount
-
 	// Получаем информацию о таблицах
 	tables, err := p.GetTables()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tables: %w", err)
 	}
 	stats.Tables = tables
+	stats.TableCount = len(tables)
 
 	// Получаем медленные запросы
 	slowQueries, err := p.GetSlowQueries()
@@ -91,114 +130,219 @@ func (p *PostgreSQLMonitor) GetStats() (*DBStats, error) {
 
 // GetTables получает информацию о таблицах
 func (p *PostgreSQLMonitor) GetTables() ([]TableInfo, error) {
-	query := `
-		SELECT 
-			t.table_name,
-			COALESCE(pg_size_pretty(pg_total_relation_size(c.oid)), '0 bytes') as size,
-			COALESCE(i.index_count, 0) as index_count
-		FROM information_schema.tables t
-		LEFT JOIN pg_class c ON c.relname = t.table_name
-		LEFT JOIN (
-			SELECT 
-				tablename,
-				count(*) as index_count
-			FROM pg_indexes 
-			WHERE schemaname = 'public'
-			GROUP BY tablename
-		) i ON i.tablename = t.table_name
-		WHERE t.table_schema = 'public'
-		ORDER BY t.table_name
-	`
-
-	rows, err := p.db.Query(query)
+	rows, err := queryStringRows(p.db, postgresTablesQuery(p.Schema()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tables: %w", err)
 	}
-	defer rows.Close()
-
-	var tables []TableInfo
-	for rows.Next() {
-		var table TableInfo
-		var size string
-		var indexCount int
-		var name string
+	tables := postgresTables(rows)
 
-		err := rows.Scan(&name, &size, &indexCount)
-		if err != nil {
-			continue
-		}
+	// Состояние vacuum дополнительное: без pg_stat_progress_vacuum (до 9.6) таблицы показываются без него
+	if health, err := queryStringRows(p.db, postgresTableHealthQuery(p.Schema())); err == nil {
+		attachTableHealth(tables, postgresTableHealth(health))
+	}
 
-		table.Name = name
-		table.Size = size
-		table.Indexes = indexCount
-		table.LastUpdate = time.Now()
+	return tables, nil
+}
 
-		tables = append(tables, table)
+// CountRows точно считает строки таблицы через COUNT(*); на больших таблицах это долго
+func (p *PostgreSQLMonitor) CountRows(table TableInfo) (int64, error) {
+	var count int64
+	if err := p.db.QueryRow(countRowsQuery("postgresql", table)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
 	}
+	return count, nil
+}
 
-	return tables, nil
+// ListSchemas возвращает пользовательские схемы базы
+func (p *PostgreSQLMonitor) ListSchemas() ([]string, error) {
+	rows, err := queryStringRows(p.db, postgresSchemasQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schemas: %w", err)
+	}
+	return firstColumn(rows), nil
 }
 
 // GetSlowQueries получает медленные запросы (если включен pg_stat_statements)
 func (p *PostgreSQLMonitor) GetSlowQueries() ([]SlowQuery, error) {
 	// Проверяем, включен ли pg_stat_statements
 	var exists bool
-	err := p.db.QueryRow(`
-		SELECT EXISTS (
-			SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements'
-		)
-	`).Scan(&exists)
+	err := p.db.QueryRow(postgresStatementsExistsQuery).Scan(&exists)
 	if err != nil || !exists {
 		return []SlowQuery{}, nil
 	}
 
+	var version int
+	if err := p.db.QueryRow("SHOW server_version_num").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	rows, err := queryStringRows(p.db, postgresSlowQueriesQuery(version, p.SlowQueryOptions()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query slow queries: %w", err)
+	}
+
+	return postgresSlowQueries(rows), nil
+}
+
+// ResetSlowQueries сбрасывает статистику pg_stat_statements
+func (p *PostgreSQLMonitor) ResetSlowQueries() error {
+	if _, err := p.db.Exec(postgresResetStatementsQuery); err != nil {
+		return fmt.Errorf("failed to reset pg_stat_statements: %w", err)
+	}
+	return nil
+}
+
+// GetConnections получает список соединений из pg_stat_activity
+func (p *PostgreSQLMonitor) GetConnections() ([]ConnectionInfo, error) {
 	query := `
 		SELECT 
-			query,
-			mean_time,
-			calls,
-			usename
-		FROM pg_stat_statements 
-		JOIN pg_user ON pg_stat_statements.userid = pg_user.usesysid
-		WHERE mean_time > 1000  -- медленные запросы (>1 сек)
-		ORDER BY mean_time DESC 
-		LIMIT 10
+			pid,
+			COALESCE(usename, ''),
+			COALESCE(host(client_addr), 'local'),
+			COALESCE(state, ''),
+			COALESCE(query, ''),
+			COALESCE(EXTRACT(EPOCH FROM now() - query_start), 0),
+			COALESCE(wait_event_type || ':' || wait_event, '')
+		FROM pg_stat_activity 
+		WHERE pid <> pg_backend_pid()
+		  AND backend_type = 'client backend'
+		ORDER BY query_start NULLS LAST
 	`
 
 	rows, err := p.db.Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query slow queries: %w", err)
+		return nil, fmt.Errorf("failed to query connections: %w", err)
 	}
 	defer rows.Close()
 
-	var slowQueries []SlowQuery
+	var connections []ConnectionInfo
 	for rows.Next() {
-		var query string
-		var meanTime float64
-		var calls int64
-		var username string
+		var conn ConnectionInfo
+		var pid int64
+		var ageSec float64
 
-		err := rows.Scan(&query, &meanTime, &calls, &username)
+		err := rows.Scan(&pid, &conn.User, &conn.ClientAddr, &conn.State,
+			&conn.Query, &ageSec, &conn.WaitEvent)
 		if err != nil {
 			continue
 		}
 
-		// Обрезаем длинный запрос
-		if len(query) > 100 {
-			query = query[:97] + "..."
+		conn.ID = fmt.Sprintf("%d", pid)
+		conn.QueryAge = time.Duration(ageSec * float64(time.Second))
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// GetLocks получает сессии, участвующие в блокировках (pg_locks + pg_blocking_pids)
+func (p *PostgreSQLMonitor) GetLocks() ([]LockInfo, error) {
+	rows, err := p.db.Query(postgresLocksQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query locks: %w", err)
+	}
+	defer rows.Close()
+
+	var locks []LockInfo
+	for rows.Next() {
+		var lock LockInfo
+		var pid int64
+		var blockedBy string
+		var waitSec float64
+
+		err := rows.Scan(&pid, &lock.User, &lock.State, &blockedBy, &lock.LockType,
+			&lock.Mode, &lock.Relation, &waitSec, &lock.Query)
+		if err != nil {
+			continue
 		}
 
-		slowQuery := SlowQuery{
-			Query:     query,
-			Duration:  time.Duration(meanTime * float64(time.Millisecond)),
-			Timestamp: time.Now(),
-			User:      username,
+		lock.ID = fmt.Sprintf("%d", pid)
+		lock.BlockedBy = splitIDs(blockedBy)
+		lock.WaitDuration = time.Duration(waitSec * float64(time.Second))
+
+		locks = append(locks, lock)
+	}
+
+	return locks, nil
+}
+
+// GetReplication получает реплики, состояние standby и слоты репликации
+func (p *PostgreSQLMonitor) GetReplication() (*ReplicationStatus, error) {
+	var inRecovery bool
+	if err := p.db.QueryRow(postgresRecoveryQuery).Scan(&inRecovery); err != nil {
+		return nil, fmt.Errorf("failed to get recovery status: %w", err)
+	}
+
+	replicas, err := queryStringRows(p.db, postgresReplicasQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replicas: %w", err)
+	}
+
+	var standby []string
+	if inRecovery {
+		rows, err := queryStringRows(p.db, postgresStandbyQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query standby status: %w", err)
 		}
+		if len(rows) > 0 {
+			standby = rows[0]
+		}
+	}
 
-		slowQueries = append(slowQueries, slowQuery)
+	slots, err := queryStringRows(p.db, postgresSlotsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replication slots: %w", err)
+	}
+
+	return postgresReplication(fmt.Sprint(inRecovery), replicas, standby, slots), nil
+}
+
+// GetIndexes получает индексы со статистикой сканирований и анализирует их
+func (p *PostgreSQLMonitor) GetIndexes() (*IndexReport, error) {
+	rows, err := queryStringRows(p.db, postgresIndexesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
 	}
 
-	return slowQueries, nil
+	scans, err := queryStringRows(p.db, postgresTableScansQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table scans: %w", err)
+	}
+
+	return buildIndexReport("postgresql", postgresIndexes(rows), tableScans(scans)), nil
+}
+
+// Explain строит план запроса; с analyze запрос выполняется в транзакции, которая затем откатывается
+func (p *PostgreSQLMonitor) Explain(query string, analyze bool) (*ExplainPlan, error) {
+	var version int
+	if err := p.db.QueryRow("SHOW server_version_num").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	statement, generic, err := postgresExplainStatement(query, analyze, version)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var raw string
+	if err := tx.QueryRow(statement).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	plan, err := parsePostgresPlan(raw)
+	if err != nil {
+		return nil, err
+	}
+	plan.Query = query
+	plan.Generic = generic
+	return plan, nil
 }
 
 // KillConnection завершает соединение по ID
@@ -0,0 +1,371 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout ограничение на одну команду к Redis
+const redisTimeout = 5 * time.Second
+
+// RedisMonitor мониторинг Redis через INFO, SLOWLOG и CLIENT LIST
+type RedisMonitor struct {
+	client   *redis.Client
+	counters counterTracker
+
+	slowQuerySettings
+	schemaSettings
+}
+
+// NewRedisMonitor создает новый монитор для Redis
+func NewRedisMonitor() *RedisMonitor {
+	return &RedisMonitor{}
+}
+
+// Connect подключается к Redis по redis:// или rediss:// URL
+func (r *RedisMonitor) Connect(connectionString string) error {
+	opts, err := redis.ParseURL(connectionString)
+	if err != nil {
+		return fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+
+	// Монитор выполняет команды последовательно; одно соединение нужно и для
+	// исключения самого себя из CLIENT LIST
+	opts.PoolSize = 1
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	r.client = client
+	return nil
+}
+
+// info выполняет INFO и раскладывает ответ в плоскую карту поле -> значение
+func (r *RedisMonitor) info(ctx context.Context, sections ...string) (map[string]string, error) {
+	raw, err := r.client.Info(ctx, sections...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[key] = value
+		}
+	}
+	return fields, nil
+}
+
+// parseRedisPairs разбирает значения вида "keys=1,expires=0,avg_ttl=0"
+func parseRedisPairs(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if key, val, ok := strings.Cut(pair, "="); ok {
+			pairs[key] = val
+		}
+	}
+	return pairs
+}
+
+// infoInt читает целое поле INFO, отсутствующее поле - 0
+func infoInt(fields map[string]string, key string) int64 {
+	value, _ := strconv.ParseInt(fields[key], 10, 64)
+	return value
+}
+
+// GetStats получает статистику Redis
+func (r *RedisMonitor) GetStats() (*DBStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	stats := &DBStats{
+		LastUpdate: time.Now(),
+	}
+
+	fields, err := r.info(ctx, "server", "clients", "memory", "stats", "keyspace", "commandstats")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Redis info: %w", err)
+	}
+
+	stats.ActiveConnections = int(infoInt(fields, "connected_clients"))
+
+	// CONFIG GET может быть запрещен через rename-command, тогда лимит неизвестен
+	if config, err := r.client.ConfigGet(ctx, "maxclients").Result(); err == nil {
+		stats.MaxConnections, _ = strconv.Atoi(config["maxclients"])
+	}
+
+	// Время выполнения суммируется по commandstats
+	sample := counterSample{
+		at:      time.Now(),
+		queries: float64(infoInt(fields, "total_commands_processed")),
+		errors:  float64(infoInt(fields, "total_error_replies") + infoInt(fields, "rejected_connections")),
+	}
+	for key, value := range fields {
+		if !strings.HasPrefix(key, "cmdstat_") {
+			continue
+		}
+		usec, _ := strconv.ParseInt(parseRedisPairs(value)["usec"], 10, 64)
+		sample.busyTime += time.Duration(usec) * time.Microsecond
+	}
+	r.counters.update(sample, stats)
+
+	stats.DatabaseSize = FormatSize(infoInt(fields, "used_memory"))
+
+	hitRatio := "n/a"
+	hits, misses := infoInt(fields, "keyspace_hits"), infoInt(fields, "keyspace_misses")
+	if hits+misses > 0 {
+		hitRatio = fmt.Sprintf("%.1f%%", float64(hits)/float64(hits+misses)*100)
+	}
+
+	stats.Properties = append(stats.Properties,
+		DBProperty{Name: "Версия", Value: fields["redis_version"]},
+		DBProperty{Name: "Заблокировано клиентов", Value: fields["blocked_clients"]},
+		DBProperty{Name: "Пик памяти", Value: FormatSize(infoInt(fields, "used_memory_peak"))},
+		DBProperty{Name: "Фрагментация", Value: fields["mem_fragmentation_ratio"]},
+		DBProperty{Name: "Операций/сек", Value: fields["instantaneous_ops_per_sec"]},
+		DBProperty{Name: "Попадания", Value: hitRatio},
+		DBProperty{Name: "Вытеснено ключей", Value: fields["evicted_keys"]},
+	)
+
+	stats.Tables = r.keyspace(fields)
+	stats.TableCount = len(stats.Tables)
+
+	slowQueries, err := r.GetSlowQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slow queries: %w", err)
+	}
+	stats.SlowQueries = slowQueries
+
+	return stats, nil
+}
+
+// keyspace превращает секцию Keyspace в список логических БД (db0, db1, ...)
+func (r *RedisMonitor) keyspace(fields map[string]string) []TableInfo {
+	var tables []TableInfo
+	for key, value := range fields {
+		if !strings.HasPrefix(key, "db") {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimPrefix(key, "db")); err != nil {
+			continue
+		}
+
+		pairs := parseRedisPairs(value)
+		keys, _ := strconv.ParseInt(pairs["keys"], 10, 64)
+		tables = append(tables, TableInfo{
+			Name:       key,
+			Size:       "n/a",
+			Rows:       keys,
+			RowsExact:  true,
+			LastUpdate: time.Now(),
+		})
+	}
+
+	// Порядок полей в карте случайный, сортируем db0, db1, ..., db10 по номеру
+	sort.Slice(tables, func(i, j int) bool {
+		ni, _ := strconv.Atoi(strings.TrimPrefix(tables[i].Name, "db"))
+		nj, _ := strconv.Atoi(strings.TrimPrefix(tables[j].Name, "db"))
+		return ni < nj
+	})
+	return tables
+}
+
+// GetTables получает логические БД Redis с количеством ключей
+func (r *RedisMonitor) GetTables() ([]TableInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	fields, err := r.info(ctx, "keyspace")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Redis keyspace: %w", err)
+	}
+	return r.keyspace(fields), nil
+}
+
+// CountRows возвращает текущее количество ключей логической БД: в INFO keyspace оно точное
+func (r *RedisMonitor) CountRows(table TableInfo) (int64, error) {
+	tables, err := r.GetTables()
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range tables {
+		if t.Name == table.Name {
+			return t.Rows, nil
+		}
+	}
+	// Пустые БД в INFO keyspace не выводятся
+	return 0, nil
+}
+
+// GetSlowQueries получает записи SLOWLOG GET. Порог задается на сервере (slowlog-log-slower-than),
+// из настроек используется только количество записей
+func (r *RedisMonitor) GetSlowQueries() ([]SlowQuery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	entries, err := r.client.SlowLogGet(ctx, int64(r.SlowQueryOptions().Limit)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query slowlog: %w", err)
+	}
+
+	slowQueries := []SlowQuery{}
+	for _, entry := range entries {
+		query := strings.Join(entry.Args, " ")
+		if len(query) > 100 {
+			query = query[:97] + "..."
+		}
+
+		user := entry.ClientName
+		if user == "" {
+			user = entry.ClientAddr
+		}
+
+		slowQueries = append(slowQueries, SlowQuery{
+			Query:     query,
+			Duration:  entry.Duration,
+			Timestamp: entry.Time,
+			User:      user,
+		})
+	}
+
+	return slowQueries, nil
+}
+
+// ResetSlowQueries очищает журнал через SLOWLOG RESET
+func (r *RedisMonitor) ResetSlowQueries() error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := r.client.Do(ctx, "SLOWLOG", "RESET").Err(); err != nil {
+		return fmt.Errorf("failed to reset slowlog: %w", err)
+	}
+	return nil
+}
+
+// GetConnections получает клиентов из CLIENT LIST, кроме собственного соединения монитора
+func (r *RedisMonitor) GetConnections() ([]ConnectionInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	ownID, err := r.client.ClientID(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client id: %w", err)
+	}
+
+	list, err := r.client.ClientList(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query clients: %w", err)
+	}
+
+	var connections []ConnectionInfo
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		client := make(map[string]string)
+		for _, field := range strings.Fields(line) {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				client[key] = value
+			}
+		}
+		if client["id"] == strconv.FormatInt(ownID, 10) {
+			continue
+		}
+
+		conn := ConnectionInfo{
+			ID:         client["id"],
+			User:       client["user"],
+			ClientAddr: client["addr"],
+			State:      "idle",
+			Query:      client["cmd"],
+		}
+		if client["name"] != "" {
+			conn.User = strings.TrimSpace(conn.User + " " + client["name"])
+		}
+
+		idle, _ := strconv.ParseInt(client["idle"], 10, 64)
+		conn.QueryAge = time.Duration(idle) * time.Second
+
+		// Флаг b - клиент ждет в блокирующей команде (BLPOP, XREAD BLOCK, ...)
+		switch {
+		case strings.Contains(client["flags"], "b"):
+			conn.State = "blocked"
+			conn.WaitEvent = "blocking command"
+		case idle == 0:
+			conn.State = "active"
+		}
+
+		connections = append(connections, conn)
+	}
+
+	return connections, nil
+}
+
+// GetLocks в Redis нет блокировок между сессиями
+func (r *RedisMonitor) GetLocks() ([]LockInfo, error) {
+	return []LockInfo{}, nil
+}
+
+// GetReplication репликация Redis пока не отслеживается
+func (r *RedisMonitor) GetReplication() (*ReplicationStatus, error) {
+	return &ReplicationStatus{}, nil
+}
+
+// GetIndexes у Redis нет вторичных индексов
+func (r *RedisMonitor) GetIndexes() (*IndexReport, error) {
+	return &IndexReport{}, nil
+}
+
+// ListSchemas выбор схемы для Redis не поддерживается
+func (r *RedisMonitor) ListSchemas() ([]string, error) {
+	return nil, fmt.Errorf("schema selection is not supported for Redis")
+}
+
+// Explain планы запросов показываются только для PostgreSQL и MySQL
+func (r *RedisMonitor) Explain(query string, analyze bool) (*ExplainPlan, error) {
+	return nil, fmt.Errorf("EXPLAIN is not supported for Redis")
+}
+
+// KillConnection отключает клиента через CLIENT KILL ID
+func (r *RedisMonitor) KillConnection(connectionID string) error {
+	if _, err := strconv.ParseInt(connectionID, 10, 64); err != nil {
+		return fmt.Errorf("invalid client id %q", connectionID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	killed, err := r.client.ClientKillByFilter(ctx, "ID", connectionID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to kill connection %s: %w", connectionID, err)
+	}
+	if killed == 0 {
+		return fmt.Errorf("failed to kill connection %s: no such client", connectionID)
+	}
+	return nil
+}
+
+// Close закрывает соединение с Redis
+func (r *RedisMonitor) Close() error {
+	if r.client != nil {
+		return r.client.Close()
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package database
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRedis минимальный сервер RESP2: на каждую команду отвечает заранее заданным ответом,
+// на неизвестные - ошибкой (HELLO тоже, поэтому go-redis остается на RESP2)
+type fakeRedis struct {
+	listener net.Listener
+	replies  map[string]string // "INFO", "SLOWLOG GET", ... -> сырой ответ RESP
+}
+
+func newFakeRedis(t *testing.T, replies map[string]string) *fakeRedis {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeRedis{listener: listener, replies: replies}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readRESPCommand(reader)
+		if err != nil {
+			return
+		}
+
+		name := strings.ToUpper(args[0])
+		reply, ok := s.replies[name]
+		if !ok && len(args) > 1 {
+			reply, ok = s.replies[name+" "+strings.ToUpper(args[1])]
+		}
+		if !ok {
+			reply = "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readRESPCommand читает команду клиента: массив bulk-строк
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command line %q", line)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+// respBulk кодирует строку как bulk-строку RESP
+func respBulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+// respArray кодирует массив из уже закодированных элементов
+func respArray(items ...string) string {
+	return fmt.Sprintf("*%d\r\n", len(items)) + strings.Join(items, "")
+}
+
+// respInt кодирует целое число
+func respInt(value int64) string {
+	return fmt.Sprintf(":%d\r\n", value)
+}
+
+const fakeRedisInfo = "# Server\r\n" +
+	"redis_version:7.2.4\r\n" +
+	"\r\n" +
+	"# Clients\r\n" +
+	"connected_clients:7\r\n" +
+	"blocked_clients:1\r\n" +
+	"\r\n" +
+	"# Memory\r\n" +
+	"used_memory:1048576\r\n" +
+	"used_memory_peak:2097152\r\n" +
+	"mem_fragmentation_ratio:1.25\r\n" +
+	"\r\n" +
+	"# Stats\r\n" +
+	"total_commands_processed:1000\r\n" +
+	"instantaneous_ops_per_sec:12\r\n" +
+	"keyspace_hits:75\r\n" +
+	"keyspace_misses:25\r\n" +
+	"evicted_keys:0\r\n" +
+	"\r\n" +
+	"# Commandstats\r\n" +
+	"cmdstat_get:calls=10,usec=150,usec_per_call=15.00,rejected_calls=0,failed_calls=0\r\n" +
+	"cmdstat_set:calls=5,usec=50,usec_per_call=10.00,rejected_calls=0,failed_calls=0\r\n" +
+	"\r\n" +
+	"# Keyspace\r\n" +
+	"db10:keys=3,expires=0,avg_ttl=0\r\n" +
+	"db0:keys=42,expires=2,avg_ttl=1000\r\n" +
+	"db2:keys=5,expires=0,avg_ttl=0\r\n"
+
+func newTestRedis(t *testing.T) *RedisMonitor {
+	t.Helper()
+
+	longArg := strings.Repeat("x", 200)
+	server := newFakeRedis(t, map[string]string{
+		"PING":       "+PONG\r\n",
+		"INFO":       respBulk(fakeRedisInfo),
+		"CONFIG GET": respArray(respBulk("maxclients"), respBulk("10000")),
+		"SLOWLOG GET": respArray(
+			respArray(respInt(2), respInt(1700000100), respInt(25000),
+				respArray(respBulk("HGETALL"), respBulk("users")), respBulk("10.0.0.5:50412"), respBulk("worker")),
+			respArray(respInt(1), respInt(1700000000), respInt(1500),
+				respArray(respBulk("SET"), respBulk("key"), respBulk(longArg)), respBulk("10.0.0.6:50413"), respBulk("")),
+		),
+	})
+
+	monitor := NewRedisMonitor()
+	if err := monitor.Connect("redis://" + server.listener.Addr().String()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { monitor.Close() })
+	return monitor
+}
+
+func TestRedisGetStats(t *testing.T) {
+	monitor := newTestRedis(t)
+
+	stats, err := monitor.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+
+	if stats.ActiveConnections != 7 {
+		t.Errorf("ActiveConnections = %d, want 7", stats.ActiveConnections)
+	}
+	if stats.MaxConnections != 10000 {
+		t.Errorf("MaxConnections = %d, want 10000", stats.MaxConnections)
+	}
+	if want := FormatSize(1048576); stats.DatabaseSize != want {
+		t.Errorf("DatabaseSize = %q, want %q", stats.DatabaseSize, want)
+	}
+
+	properties := make(map[string]string)
+	for _, property := range stats.Properties {
+		properties[property.Name] = property.Value
+	}
+	for name, want := range map[string]string{
+		"Версия": "7.2.4",
+		"Заблокировано клиентов": "1",
+		"Пик памяти":             FormatSize(2097152),
+		"Фрагментация":           "1.25",
+		"Операций/сек":           "12",
+		"Попадания":              "75.0%",
+	} {
+		if properties[name] != want {
+			t.Errorf("property %q = %q, want %q", name, properties[name], want)
+		}
+	}
+
+	if stats.TableCount != 3 || len(stats.SlowQueries) != 2 {
+		t.Errorf("TableCount = %d, SlowQueries = %d; want 3 and 2", stats.TableCount, len(stats.SlowQueries))
+	}
+}
+
+func TestRedisKeyspace(t *testing.T) {
+	monitor := newTestRedis(t)
+
+	tables, err := monitor.GetTables()
+	if err != nil {
+		t.Fatalf("GetTables: %v", err)
+	}
+
+	want := []struct {
+		name string
+		keys int64
+	}{
+		{"db0", 42},
+		{"db2", 5},
+		{"db10", 3},
+	}
+	if len(tables) != len(want) {
+		t.Fatalf("GetTables returned %d databases, want %d: %+v", len(tables), len(want), tables)
+	}
+	for i, w := range want {
+		if tables[i].Name != w.name || tables[i].Rows != w.keys || !tables[i].RowsExact {
+			t.Errorf("tables[%d] = %s with %d keys (exact %v), want %s with %d", i,
+				tables[i].Name, tables[i].Rows, tables[i].RowsExact, w.name, w.keys)
+		}
+	}
+
+	for name, want := range map[string]int64{"db2": 5, "db1": 0} {
+		count, err := monitor.CountRows(TableInfo{Name: name})
+		if err != nil {
+			t.Fatalf("CountRows(%s): %v", name, err)
+		}
+		if count != want {
+			t.Errorf("CountRows(%s) = %d, want %d", name, count, want)
+		}
+	}
+}
+
+func TestRedisGetSlowQueries(t *testing.T) {
+	monitor := newTestRedis(t)
+
+	queries, err := monitor.GetSlowQueries()
+	if err != nil {
+		t.Fatalf("GetSlowQueries: %v", err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("GetSlowQueries returned %d entries, want 2", len(queries))
+	}
+
+	first := queries[0]
+	if first.Query != "HGETALL users" || first.Duration != 25*time.Millisecond ||
+		!first.Timestamp.Equal(time.Unix(1700000100, 0)) || first.User != "worker" {
+		t.Errorf("queries[0] = %+v", first)
+	}
+
+	// Без имени клиента показывается адрес, длинные команды обрезаются до 100 символов
+	second := queries[1]
+	if second.User != "10.0.0.6:50413" {
+		t.Errorf("queries[1].User = %q, want client address", second.User)
+	}
+	if len(second.Query) != 100 || !strings.HasPrefix(second.Query, "SET key xxx") || !strings.HasSuffix(second.Query, "...") {
+		t.Errorf("queries[1].Query = %q, want truncated to 100 characters", second.Query)
+	}
+}
+
+func TestParseRedisPairs(t *testing.T) {
+	pairs := parseRedisPairs("keys=42,expires=2,avg_ttl=1000,broken")
+	if len(pairs) != 3 || pairs["keys"] != "42" || pairs["expires"] != "2" || pairs["avg_ttl"] != "1000" {
+		t.Errorf("parseRedisPairs() = %v", pairs)
+	}
+}
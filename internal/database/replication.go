@@ -0,0 +1,380 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Роли сервера в репликации
+const (
+	ReplicationRolePrimary = "primary"
+	ReplicationRoleReplica = "replica"
+)
+
+// ReplicationStatus состояние репликации сервера
+type ReplicationStatus struct {
+	Role     string            // primary, replica или пусто, если репликация не настроена
+	Replicas []ReplicaInfo     // подключенные реплики (на primary и каскадной реплике)
+	Upstream *UpstreamInfo     // источник, если сервер сам является репликой
+	Slots    []ReplicationSlot // слоты репликации PostgreSQL
+}
+
+// ReplicaInfo реплика, подключенная к серверу
+type ReplicaInfo struct {
+	Name           string // application_name в PostgreSQL, server_id в MySQL
+	Client         string
+	State          string
+	SyncState      string
+	SentLagBytes   int64 // WAL, еще не отправленный реплике; -1, если неизвестно
+	WriteLag       time.Duration
+	FlushLag       time.Duration
+	ReplayLag      time.Duration // задержки -1, если неизвестны (MySQL не сообщает их на источнике)
+	ReplayLagBytes int64         // WAL, еще не примененный репликой; -1, если неизвестно
+}
+
+// UpstreamInfo источник репликации для сервера-реплики
+type UpstreamInfo struct {
+	Source     string
+	IOState    string        // статус WAL receiver в PostgreSQL, Replica_IO_Running в MySQL
+	SQLState   string        // Replica_SQL_Running в MySQL
+	ReceivePos string        // последний полученный LSN или binlog:позиция
+	ReplayPos  string        // последний примененный LSN или binlog:позиция
+	LagBytes   int64         // получено, но еще не применено
+	Delay      time.Duration // -1, если задержка неизвестна (например, остановлен SQL поток)
+	LastError  string
+}
+
+// ReplicationSlot слот репликации и удерживаемый им WAL
+type ReplicationSlot struct {
+	Name          string
+	Type          string
+	Active        bool
+	RetainedBytes int64
+}
+
+// Metrics метрики отставания для правил алертинга
+func (r *ReplicationStatus) Metrics() map[string]float64 {
+	metrics := make(map[string]float64)
+	if r == nil || r.Role == "" {
+		return metrics
+	}
+
+	var lag time.Duration
+	var lagBytes, retained int64
+	for _, replica := range r.Replicas {
+		lag = max(lag, replica.ReplayLag)
+		lagBytes = max(lagBytes, replica.ReplayLagBytes)
+	}
+	if r.Upstream != nil {
+		lag = max(lag, r.Upstream.Delay)
+		lagBytes = max(lagBytes, r.Upstream.LagBytes)
+	}
+	for _, slot := range r.Slots {
+		retained = max(retained, slot.RetainedBytes)
+	}
+
+	metrics["db.replication_lag_seconds"] = lag.Seconds()
+	metrics["db.replication_lag_bytes"] = float64(lagBytes)
+	metrics["db.replicas"] = float64(len(r.Replicas))
+	if len(r.Slots) > 0 {
+		metrics["db.replication_slot_retained_bytes"] = float64(retained)
+	}
+	return metrics
+}
+
+// FormatLag форматирует задержку репликации; -1 означает, что она неизвестна
+func FormatLag(lag time.Duration) string {
+	if lag < 0 {
+		return "n/a"
+	}
+	if lag < time.Second {
+		return lag.Round(time.Millisecond).String()
+	}
+	return lag.Round(100 * time.Millisecond).String()
+}
+
+// FormatLagBytes форматирует отставание в байтах; -1 означает, что оно неизвестно
+func FormatLagBytes(bytes int64) string {
+	if bytes < 0 {
+		return "n/a"
+	}
+	return FormatSize(bytes)
+}
+
+// postgresCurrentLSN текущая позиция WAL: на реплике pg_current_wal_lsn() недоступна
+const postgresCurrentLSN = "CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END"
+
+// postgresRecoveryQuery признак standby
+const postgresRecoveryQuery = "SELECT pg_is_in_recovery()"
+
+// postgresReplicasQuery реплики из pg_stat_replication с отставанием в байтах и секундах
+const postgresReplicasQuery = `
+	SELECT
+		COALESCE(application_name, ''),
+		COALESCE(host(client_addr), 'local'),
+		COALESCE(state, ''),
+		COALESCE(sync_state, ''),
+		COALESCE(pg_wal_lsn_diff(` + postgresCurrentLSN + `, sent_lsn), 0)::bigint,
+		COALESCE(EXTRACT(EPOCH FROM write_lag), 0),
+		COALESCE(EXTRACT(EPOCH FROM flush_lag), 0),
+		COALESCE(EXTRACT(EPOCH FROM replay_lag), 0),
+		COALESCE(pg_wal_lsn_diff(` + postgresCurrentLSN + `, replay_lsn), 0)::bigint
+	FROM pg_stat_replication
+	ORDER BY application_name
+`
+
+// postgresStandbyQuery состояние standby. Задержка считается от времени последней примененной
+// транзакции, поэтому растет и при простое primary. sender_host есть только с PostgreSQL 11
+const postgresStandbyQuery = `
+	SELECT
+		COALESCE((SELECT to_jsonb(r) ->> 'sender_host' FROM pg_stat_wal_receiver r LIMIT 1), ''),
+		COALESCE((SELECT status FROM pg_stat_wal_receiver LIMIT 1), 'stopped'),
+		COALESCE(pg_last_wal_receive_lsn()::text, ''),
+		COALESCE(pg_last_wal_replay_lsn()::text, ''),
+		COALESCE(pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn()), 0)::bigint,
+		COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), -1)
+`
+
+// postgresSlotsQuery слоты репликации и объем WAL, который они не дают удалить
+const postgresSlotsQuery = `
+	SELECT
+		slot_name,
+		slot_type,
+		active,
+		COALESCE(pg_wal_lsn_diff(` + postgresCurrentLSN + `, restart_lsn), 0)::bigint
+	FROM pg_replication_slots
+	ORDER BY slot_name
+`
+
+// postgresReplication собирает статус из результатов запросов, общих для прямого подключения и Docker.
+// Значения приходят строками: bool как true/false или t/f (psql)
+func postgresReplication(inRecovery string, replicas [][]string, standby []string, slots [][]string) *ReplicationStatus {
+	status := &ReplicationStatus{}
+
+	for _, row := range replicas {
+		status.Replicas = append(status.Replicas, ReplicaInfo{
+			Name:           row[0],
+			Client:         row[1],
+			State:          row[2],
+			SyncState:      row[3],
+			SentLagBytes:   parseInt64(row[4]),
+			WriteLag:       parseSeconds(row[5]),
+			FlushLag:       parseSeconds(row[6]),
+			ReplayLag:      parseSeconds(row[7]),
+			ReplayLagBytes: parseInt64(row[8]),
+		})
+	}
+
+	for _, row := range slots {
+		status.Slots = append(status.Slots, ReplicationSlot{
+			Name:          row[0],
+			Type:          row[1],
+			Active:        parseBool(row[2]),
+			RetainedBytes: parseInt64(row[3]),
+		})
+	}
+
+	switch {
+	case parseBool(inRecovery) && len(standby) == 6:
+		status.Role = ReplicationRoleReplica
+		status.Upstream = &UpstreamInfo{
+			Source:     standby[0],
+			IOState:    standby[1],
+			ReceivePos: standby[2],
+			ReplayPos:  standby[3],
+			LagBytes:   parseInt64(standby[4]),
+			Delay:      parseSeconds(standby[5]),
+		}
+	case len(status.Replicas) > 0 || len(status.Slots) > 0:
+		status.Role = ReplicationRolePrimary
+	}
+
+	return status
+}
+
+// mysqlReplication собирает статус из SHOW REPLICA STATUS и SHOW REPLICAS (или их SLAVE-аналогов).
+// При multi-source репликации показывается первый канал
+func mysqlReplication(replicaStatus, hosts []map[string]string) *ReplicationStatus {
+	status := &ReplicationStatus{}
+
+	for _, host := range hosts {
+		// Host пустой, если на реплике не задан report_host
+		client := pickField(host, "Host")
+		if port := pickField(host, "Port"); client != "" && port != "" {
+			client += ":" + port
+		}
+		status.Replicas = append(status.Replicas, ReplicaInfo{
+			Name:           "server_id " + pickField(host, "Server_Id", "Server_id"),
+			Client:         client,
+			State:          "connected",
+			SentLagBytes:   -1,
+			WriteLag:       -1,
+			FlushLag:       -1,
+			ReplayLag:      -1,
+			ReplayLagBytes: -1,
+		})
+	}
+	if len(status.Replicas) > 0 {
+		status.Role = ReplicationRolePrimary
+	}
+
+	if len(replicaStatus) == 0 {
+		return status
+	}
+
+	row := replicaStatus[0]
+	upstream := &UpstreamInfo{
+		Source:   pickField(row, "Source_Host", "Master_Host"),
+		IOState:  pickField(row, "Replica_IO_Running", "Slave_IO_Running"),
+		SQLState: pickField(row, "Replica_SQL_Running", "Slave_SQL_Running"),
+		ReceivePos: fmt.Sprintf("%s:%s",
+			pickField(row, "Source_Log_File", "Master_Log_File"),
+			pickField(row, "Read_Source_Log_Pos", "Read_Master_Log_Pos")),
+		ReplayPos: fmt.Sprintf("%s:%s",
+			pickField(row, "Relay_Source_Log_File", "Relay_Master_Log_File"),
+			pickField(row, "Exec_Source_Log_Pos", "Exec_Master_Log_Pos")),
+		Delay: -1,
+	}
+	if port := pickField(row, "Source_Port", "Master_Port"); port != "" {
+		upstream.Source += ":" + port
+	}
+	// Seconds_Behind_Source равен NULL, когда SQL поток остановлен
+	if seconds, err := strconv.ParseInt(pickField(row, "Seconds_Behind_Source", "Seconds_Behind_Master"), 10, 64); err == nil {
+		upstream.Delay = time.Duration(seconds) * time.Second
+	}
+
+	var errors []string
+	for _, key := range []string{"Last_IO_Error", "Last_SQL_Error"} {
+		if value := row[key]; value != "" {
+			errors = append(errors, value)
+		}
+	}
+	upstream.LastError = strings.Join(errors, "; ")
+
+	status.Role = ReplicationRoleReplica
+	status.Upstream = upstream
+	return status
+}
+
+// scanStringMaps читает строки результата с заранее неизвестным набором колонок; NULL становится ""
+func scanStringMaps(rows *sql.Rows) ([]map[string]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			row[column] = values[i].String
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// queryStringRows выполняет запрос и возвращает все колонки строками; NULL становится ""
+func queryStringRows(db *sql.DB, query string) ([][]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result [][]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make([]string, len(columns))
+		for i := range values {
+			row[i] = values[i].String
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// parseVerticalRows разбирает вывод mysql с \G: блоки "*** N. row ***" со строками "Поле: значение"
+func parseVerticalRows(output string) []map[string]string {
+	var result []map[string]string
+	var row map[string]string
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "***") {
+			row = make(map[string]string)
+			result = append(result, row)
+			continue
+		}
+		if row == nil {
+			continue
+		}
+		if key, value, ok := strings.Cut(trimmed, ":"); ok {
+			row[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return result
+}
+
+// pickField возвращает первое непустое поле из списка имен (новые и старые названия колонок MySQL)
+func pickField(row map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := row[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func parseInt64(value string) int64 {
+	result, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return result
+}
+
+// parseSeconds переводит секунды (в том числе дробные) в Duration; отрицательное значение - -1
+func parseSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+	if seconds < 0 {
+		return -1
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func parseBool(value string) bool {
+	switch strings.TrimSpace(value) {
+	case "t", "true", "1":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AllSchemas значение схемы, при котором показываются таблицы всех пользовательских схем
+// (для MySQL - всех баз сервера)
+const AllSchemas = "*"
+
+// schemaSettings встраивается в мониторы и хранит выбранную схему PostgreSQL или базу MySQL;
+// пустая строка - схема по умолчанию: public для PostgreSQL, DATABASE() для MySQL
+type schemaSettings struct {
+	schema string
+}
+
+// SetSchema выбирает схему (базу для MySQL), таблицы которой показываются; AllSchemas - все схемы
+func (s *schemaSettings) SetSchema(schema string) {
+	s.schema = strings.TrimSpace(schema)
+}
+
+// Schema возвращает выбранную схему; пустая строка - схема по умолчанию
+func (s *schemaSettings) Schema() string {
+	return s.schema
+}
+
+// QualifiedName имя таблицы вместе со схемой (базой для MySQL), если она известна
+func (t TableInfo) QualifiedName() string {
+	if t.Schema == "" {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// quoteLiteral экранирует строку для подстановки в SQL PostgreSQL как литерал: запросы в Docker
+// выполняются через psql без параметров
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// quoteMySQLLiteral то же для MySQL, где обратная косая черта внутри строки - экранирующий символ
+func quoteMySQLLiteral(value string) string {
+	return quoteLiteral(strings.ReplaceAll(value, `\`, `\\`))
+}
+
+// postgresSystemSchemas условие, отсекающее системные схемы PostgreSQL
+const postgresSystemSchemas = `%[1]s NOT IN ('pg_catalog', 'information_schema')
+	AND %[1]s NOT LIKE 'pg\_toast%%' AND %[1]s NOT LIKE 'pg\_temp\_%%'`
+
+// postgresSchemaFilter условие на колонку со схемой для выбранной схемы
+func postgresSchemaFilter(column, schema string) string {
+	switch schema {
+	case "":
+		return column + " = 'public'"
+	case AllSchemas:
+		return fmt.Sprintf(postgresSystemSchemas, column)
+	default:
+		return column + " = " + quoteLiteral(schema)
+	}
+}
+
+// postgresSchemasQuery пользовательские схемы для выбора в TUI
+var postgresSchemasQuery = `
+	SELECT nspname
+	FROM pg_namespace
+	WHERE ` + fmt.Sprintf(postgresSystemSchemas, "nspname") + `
+	ORDER BY nspname
+`
+
+// postgresTablesQuery таблицы выбранной схемы: схема, имя, оценка строк, размер всего, данных, индексов
+// и TOAST, количество индексов. pg_class соединяется по oid через pg_namespace, чтобы одноименные
+// таблицы разных схем не смешивались. reltuples -1 (PostgreSQL 14+) - таблица еще не анализировалась
+func postgresTablesQuery(schema string) string {
+	return `
+		SELECT
+			n.nspname,
+			c.relname,
+			c.reltuples::bigint,
+			pg_total_relation_size(c.oid),
+			pg_table_size(c.oid) - t.toast,
+			pg_indexes_size(c.oid),
+			t.toast,
+			(SELECT count(*) FROM pg_index i WHERE i.indrelid = c.oid)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		CROSS JOIN LATERAL (
+			SELECT CASE WHEN c.reltoastrelid <> 0 THEN pg_total_relation_size(c.reltoastrelid) ELSE 0 END AS toast
+		) t
+		WHERE c.relkind IN ('r', 'p', 'm') AND ` + postgresSchemaFilter("n.nspname", schema) + `
+		ORDER BY n.nspname, c.relname
+	`
+}
+
+// postgresTables разбирает строки postgresTablesQuery
+func postgresTables(rows [][]string) []TableInfo {
+	var tables []TableInfo
+	for _, row := range rows {
+		sizes := &TableSizes{
+			Total: parseInt64(row[3]),
+			Heap:  parseInt64(row[4]),
+			Index: parseInt64(row[5]),
+			Toast: parseInt64(row[6]),
+		}
+		tables = append(tables, TableInfo{
+			Schema:     row[0],
+			Name:       row[1],
+			Size:       FormatSize(sizes.Total),
+			Sizes:      sizes,
+			Rows:       parseInt64(row[2]),
+			Indexes:    int(parseInt64(row[7])),
+			LastUpdate: time.Now(),
+		})
+	}
+	return tables
+}
+
+// mysqlSystemSchemas служебные базы MySQL, которые не показываются среди пользовательских
+const mysqlSystemSchemas = "('mysql', 'information_schema', 'performance_schema', 'sys')"
+
+// mysqlSchemaFilter условие на колонку с базой для выбранной базы
+func mysqlSchemaFilter(column, schema string) string {
+	switch schema {
+	case "":
+		return column + " = DATABASE()"
+	case AllSchemas:
+		return column + " NOT IN " + mysqlSystemSchemas
+	default:
+		return column + " = " + quoteMySQLLiteral(schema)
+	}
+}
+
+// mysqlSchemasQuery базы сервера для выбора в TUI
+const mysqlSchemasQuery = `
+	SELECT schema_name
+	FROM information_schema.schemata
+	WHERE schema_name NOT IN ` + mysqlSystemSchemas + `
+	ORDER BY schema_name
+`
+
+// mysqlTablesQuery таблицы выбранной базы: база, имя, строк (оценка InnoDB), размер данных и индексов
+// в байтах, количество индексов. Представления отсекаются: у них нет ни строк, ни размера
+func mysqlTablesQuery(schema string) string {
+	return `
+		SELECT
+			t.table_schema,
+			t.table_name,
+			COALESCE(t.table_rows, -1),
+			COALESCE(t.data_length, 0),
+			COALESCE(t.index_length, 0),
+			(SELECT COUNT(DISTINCT s.index_name) FROM information_schema.statistics s
+				WHERE s.table_schema = t.table_schema AND s.table_name = t.table_name)
+		FROM information_schema.tables t
+		WHERE t.table_type = 'BASE TABLE' AND ` + mysqlSchemaFilter("t.table_schema", schema) + `
+		ORDER BY t.table_schema, t.table_name
+	`
+}
+
+// mysqlTables разбирает строки mysqlTablesQuery
+func mysqlTables(rows [][]string) []TableInfo {
+	var tables []TableInfo
+	for _, row := range rows {
+		sizes := &TableSizes{
+			Heap:  parseInt64(row[3]),
+			Index: parseInt64(row[4]),
+			Toast: -1,
+		}
+		sizes.Total = sizes.Heap + sizes.Index
+		tables = append(tables, TableInfo{
+			Schema:     row[0],
+			Name:       row[1],
+			Size:       FormatSize(sizes.Total),
+			Sizes:      sizes,
+			Rows:       parseInt64(row[2]),
+			Indexes:    int(parseInt64(row[5])),
+			LastUpdate: time.Now(),
+		})
+	}
+	return tables
+}
+
+// countRowsQuery точный подсчет строк таблицы; dialect как у quoteIdent
+func countRowsQuery(dialect string, table TableInfo) string {
+	return "SELECT COUNT(*) FROM " + qualifiedName(dialect, table.Schema, table.Name)
+}
+
+// firstColumn первая колонка каждой строки
+func firstColumn(rows [][]string) []string {
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, row[0])
+	}
+	return values
+}
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Сортировка медленных запросов
+const (
+	SlowQuerySortMean  = "mean"  // по среднему времени выполнения
+	SlowQuerySortTotal = "total" // по суммарному времени: частые быстрые запросы тоже нагружают сервер
+)
+
+// Значения по умолчанию для медленных запросов
+const (
+	DefaultSlowQueryThreshold = time.Second
+	DefaultSlowQueryLimit     = 10
+)
+
+// SlowQueryOptions порог, сортировка и количество медленных запросов
+type SlowQueryOptions struct {
+	Threshold time.Duration // минимальное среднее время; 0 - все запросы
+	SortBy    string        // SlowQuerySortMean или SlowQuerySortTotal
+	Limit     int
+}
+
+// DefaultSlowQueryOptions настройки по умолчанию: запросы дольше секунды по среднему времени
+func DefaultSlowQueryOptions() SlowQueryOptions {
+	return SlowQueryOptions{
+		Threshold: DefaultSlowQueryThreshold,
+		SortBy:    SlowQuerySortMean,
+		Limit:     DefaultSlowQueryLimit,
+	}
+}
+
+// slowQuerySettings встраивается в мониторы и хранит настройки медленных запросов
+type slowQuerySettings struct {
+	slowOptions *SlowQueryOptions
+}
+
+// SetSlowQueryOptions задает порог, сортировку и количество медленных запросов
+func (s *slowQuerySettings) SetSlowQueryOptions(opts SlowQueryOptions) {
+	if opts.SortBy != SlowQuerySortTotal {
+		opts.SortBy = SlowQuerySortMean
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultSlowQueryLimit
+	}
+	if opts.Threshold < 0 {
+		opts.Threshold = 0
+	}
+	s.slowOptions = &opts
+}
+
+// SlowQueryOptions возвращает текущие настройки медленных запросов
+func (s *slowQuerySettings) SlowQueryOptions() SlowQueryOptions {
+	if s.slowOptions == nil {
+		return DefaultSlowQueryOptions()
+	}
+	return *s.slowOptions
+}
+
+// Details строки статистики запроса для вывода под его текстом
+func (q *SlowQuery) Details() []string {
+	if q.Calls == 0 {
+		return []string{fmt.Sprintf("Время: %v | Пользователь: %s", q.Duration, q.User)}
+	}
+
+	timing := fmt.Sprintf("Вызовов: %d | Всего: %s | Среднее: %s | Макс: %s",
+		q.Calls, formatQueryTime(q.TotalTime), formatQueryTime(q.Duration), formatQueryTime(q.MaxTime))
+	if q.StddevTime >= 0 {
+		timing += " | σ: " + formatQueryTime(q.StddevTime)
+	}
+	timing += fmt.Sprintf(" | Строк: %d", q.Rows)
+
+	var source []string
+	if q.User != "" {
+		source = append(source, "Пользователь: "+q.User)
+	}
+	if q.Schema != "" {
+		source = append(source, "База: "+q.Schema)
+	}
+	if q.QueryID != "" {
+		source = append(source, "ID: "+q.QueryID)
+	}
+
+	lines := []string{timing}
+	if len(source) > 0 {
+		lines = append(lines, strings.Join(source, " | "))
+	}
+
+	var io []string
+	if blocks := q.SharedBlksHit + q.SharedBlksRead; blocks > 0 {
+		io = append(io, fmt.Sprintf("Буферы: попаданий %d, чтений %d (%.1f%% из кэша)",
+			q.SharedBlksHit, q.SharedBlksRead, float64(q.SharedBlksHit)/float64(blocks)*100))
+	}
+	if q.TempBlksRead+q.TempBlksWritten > 0 {
+		io = append(io, fmt.Sprintf("Temp: прочитано %d, записано %d блоков", q.TempBlksRead, q.TempBlksWritten))
+	}
+	if q.TempDiskTables > 0 {
+		io = append(io, fmt.Sprintf("Временных таблиц на диске: %d", q.TempDiskTables))
+	}
+	if len(io) > 0 {
+		lines = append(lines, strings.Join(io, " | "))
+	}
+
+	return lines
+}
+
+// formatQueryTime округляет время запроса: до микросекунд для быстрых, до миллисекунд для остальных
+func formatQueryTime(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// postgresSlowQueriesQuery запрос к pg_stat_statements. В PostgreSQL 13 колонки времени
+// переименованы: mean_time -> mean_exec_time и т.д.
+func postgresSlowQueriesQuery(serverVersion int, opts SlowQueryOptions) string {
+	suffix := "_exec_time"
+	if serverVersion < 130000 {
+		suffix = "_time"
+	}
+
+	orderBy := "mean" + suffix
+	if opts.SortBy == SlowQuerySortTotal {
+		orderBy = "total" + suffix
+	}
+
+	return fmt.Sprintf(`
+		SELECT
+			COALESCE(s.queryid::text, ''),
+			s.query,
+			COALESCE(u.usename, ''),
+			s.calls,
+			s.total%[1]s,
+			s.mean%[1]s,
+			s.max%[1]s,
+			s.stddev%[1]s,
+			s.rows,
+			s.shared_blks_hit,
+			s.shared_blks_read,
+			s.temp_blks_read,
+			s.temp_blks_written
+		FROM pg_stat_statements s
+		LEFT JOIN pg_user u ON u.usesysid = s.userid
+		WHERE s.mean%[1]s >= %[2]f
+		ORDER BY s.%[3]s DESC
+		LIMIT %[4]d
+	`, suffix, float64(opts.Threshold)/float64(time.Millisecond), orderBy, opts.Limit)
+}
+
+// postgresSlowQueries разбирает строки postgresSlowQueriesQuery; время в pg_stat_statements в миллисекундах
+func postgresSlowQueries(rows [][]string) []SlowQuery {
+	slowQueries := []SlowQuery{}
+	for _, row := range rows {
+		slowQueries = append(slowQueries, SlowQuery{
+			QueryID:         row[0],
+			Query:           row[1],
+			User:            row[2],
+			Calls:           parseInt64(row[3]),
+			TotalTime:       parseMillis(row[4]),
+			Duration:        parseMillis(row[5]),
+			MaxTime:         parseMillis(row[6]),
+			StddevTime:      parseMillis(row[7]),
+			Rows:            parseInt64(row[8]),
+			SharedBlksHit:   parseInt64(row[9]),
+			SharedBlksRead:  parseInt64(row[10]),
+			TempBlksRead:    parseInt64(row[11]),
+			TempBlksWritten: parseInt64(row[12]),
+			Timestamp:       time.Now(),
+		})
+	}
+	return slowQueries
+}
+
+// postgresStatementsExistsQuery проверка, что расширение pg_stat_statements установлено
+const postgresStatementsExistsQuery = `
+	SELECT EXISTS (
+		SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements'
+	)
+`
+
+// postgresResetStatementsQuery сброс статистики pg_stat_statements
+const postgresResetStatementsQuery = "SELECT pg_stat_statements_reset()"
+
+// mysqlSlowQueriesQuery запрос к events_statements_summary_by_digest. Таймеры performance_schema
+// в пикосекундах; sample - пример с конкретными значениями (query_sample_text есть с MySQL 8.0)
+func mysqlSlowQueriesQuery(opts SlowQueryOptions, withSample bool) string {
+	orderBy := "avg_timer_wait"
+	if opts.SortBy == SlowQuerySortTotal {
+		orderBy = "sum_timer_wait"
+	}
+
+	sample := "''"
+	if withSample {
+		sample = "COALESCE(query_sample_text, '')"
+	}
+
+	return fmt.Sprintf(`
+		SELECT
+			COALESCE(digest, ''),
+			digest_text,
+			COALESCE(schema_name, ''),
+			count_star,
+			sum_timer_wait/1000000000,
+			avg_timer_wait/1000000000,
+			max_timer_wait/1000000000,
+			sum_rows_sent,
+			sum_created_tmp_disk_tables,
+			%s
+		FROM performance_schema.events_statements_summary_by_digest
+		WHERE digest_text IS NOT NULL AND avg_timer_wait >= %d
+		ORDER BY %s DESC
+		LIMIT %d
+	`, sample, opts.Threshold.Nanoseconds()*1000, orderBy, opts.Limit)
+}
+
+// mysqlSlowQueries разбирает строки mysqlSlowQueriesQuery: время уже переведено в миллисекунды
+func mysqlSlowQueries(rows [][]string) []SlowQuery {
+	slowQueries := []SlowQuery{}
+	for _, row := range rows {
+		slowQueries = append(slowQueries, SlowQuery{
+			QueryID:        row[0],
+			Query:          row[1],
+			Schema:         row[2],
+			Calls:          parseInt64(row[3]),
+			TotalTime:      parseMillis(row[4]),
+			Duration:       parseMillis(row[5]),
+			MaxTime:        parseMillis(row[6]),
+			StddevTime:     -1,
+			Rows:           parseInt64(row[7]),
+			TempDiskTables: parseInt64(row[8]),
+			Sample:         row[9],
+			Timestamp:      time.Now(),
+		})
+	}
+	return slowQueries
+}
+
+// mysqlResetStatementsQuery сброс статистики по digest
+const mysqlResetStatementsQuery = "TRUNCATE TABLE performance_schema.events_statements_summary_by_digest"
+
+// parseMillis переводит миллисекунды (в том числе дробные) в Duration
+func parseMillis(value string) time.Duration {
+	millis, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(millis * float64(time.Millisecond))
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMySQLSlowQueriesSchema(t *testing.T) {
+	rows := [][]string{{"abc123", "SELECT * FROM orders WHERE id = ?", "shop", "10", "1500.5", "150.05", "900", "10", "0", ""}}
+
+	queries := mysqlSlowQueries(rows)
+	if len(queries) != 1 {
+		t.Fatalf("mysqlSlowQueries returned %d queries, want 1", len(queries))
+	}
+	q := queries[0]
+	if q.Schema != "shop" || q.User != "" {
+		t.Errorf("Schema = %q, User = %q; want schema shop and no user", q.Schema, q.User)
+	}
+
+	details := strings.Join(q.Details(), "\n")
+	if !strings.Contains(details, "База: shop | ID: abc123") {
+		t.Errorf("Details() = %q, want database and digest", details)
+	}
+	if strings.Contains(details, "Пользователь") {
+		t.Errorf("Details() = %q, digest has no user", details)
+	}
+}
+
+func TestSlowQueryDetailsUser(t *testing.T) {
+	q := SlowQuery{QueryID: "42", User: "app", Calls: 3, StddevTime: -1}
+
+	details := strings.Join(q.Details(), "\n")
+	if !strings.Contains(details, "Пользователь: app | ID: 42") {
+		t.Errorf("Details() = %q", details)
+	}
+}
@@ -0,0 +1,342 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteIntegrityInterval как часто повторять PRAGMA integrity_check: на больших файлах он читает всю БД
+const sqliteIntegrityInterval = 5 * time.Minute
+
+// sqlitePragmas настройки, показываемые в обзоре
+var sqlitePragmas = []string{
+	"journal_mode", "synchronous", "foreign_keys", "auto_vacuum",
+	"cache_size", "busy_timeout", "user_version", "encoding",
+}
+
+// sqliteIndexesQuery индексы пользовательских таблиц с колонками в порядке индекса (через перевод строки)
+const sqliteIndexesQuery = `
+	SELECT
+		m.name,
+		il.name,
+		il."unique",
+		il.origin = 'pk',
+		il.partial,
+		COALESCE(x.sql, ''),
+		COALESCE((
+			SELECT group_concat(name, char(10)) FROM (
+				SELECT COALESCE(ii.name, '<expr>') AS name
+				FROM pragma_index_info(il.name) ii
+				ORDER BY ii.seqno
+			)
+		), '')
+	FROM sqlite_master m
+	JOIN pragma_index_list(m.name) il
+	LEFT JOIN sqlite_master x ON x.type = 'index' AND x.name = il.name
+	WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+	ORDER BY m.name, il.name
+`
+
+// SQLiteMonitor мониторинг файла SQLite
+type SQLiteMonitor struct {
+	db   *sql.DB
+	path string
+
+	integrity   string
+	integrityAt time.Time
+
+	slowQuerySettings
+	schemaSettings
+}
+
+// NewSQLiteMonitor создает новый монитор для SQLite
+func NewSQLiteMonitor() *SQLiteMonitor {
+	return &SQLiteMonitor{}
+}
+
+// sqlitePath переводит sqlite:///abs/app.db, sqlite://rel.db и file:app.db?... в путь к файлу и DSN драйвера
+func sqlitePath(connectionString string) (path, dsn string, err error) {
+	switch {
+	case strings.HasPrefix(connectionString, "sqlite://"):
+		u, err := url.Parse(connectionString)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid SQLite connection string: %w", err)
+		}
+		path = u.Host + u.Path
+		dsn = path
+		if u.RawQuery != "" {
+			dsn = "file:" + path + "?" + u.RawQuery
+		}
+	case strings.HasPrefix(connectionString, "file:"):
+		dsn = connectionString
+		path = strings.TrimPrefix(connectionString, "file:")
+		path, _, _ = strings.Cut(path, "?")
+		path = strings.TrimPrefix(path, "//")
+	default:
+		path, dsn = connectionString, connectionString
+	}
+
+	if path == "" {
+		return "", "", fmt.Errorf("SQLite connection string has no file path")
+	}
+	return path, dsn, nil
+}
+
+// Connect открывает файл SQLite; несуществующий файл - ошибка, а не новая пустая БД
+func (m *SQLiteMonitor) Connect(connectionString string) error {
+	path, dsn, err := sqlitePath(connectionString)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SQLite: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping SQLite: %w", err)
+	}
+
+	m.db = db
+	m.path = path
+	return nil
+}
+
+// GetStats получает статистику файла SQLite
+func (m *SQLiteMonitor) GetStats() (*DBStats, error) {
+	stats := &DBStats{
+		LastUpdate: time.Now(),
+	}
+
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat database file: %w", err)
+	}
+	stats.DatabaseSize = FormatSize(info.Size())
+
+	var pageCount, pageSize, freelist int64
+	if err := m.db.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return nil, fmt.Errorf("failed to get page count: %w", err)
+	}
+	if err := m.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return nil, fmt.Errorf("failed to get page size: %w", err)
+	}
+	if err := m.db.QueryRow("PRAGMA freelist_count").Scan(&freelist); err != nil {
+		return nil, fmt.Errorf("failed to get freelist count: %w", err)
+	}
+
+	walSize := "нет"
+	if wal, err := os.Stat(m.path + "-wal"); err == nil {
+		walSize = FormatSize(wal.Size())
+	}
+
+	stats.Properties = append(stats.Properties,
+		DBProperty{Name: "Страниц", Value: fmt.Sprintf("%d × %d B", pageCount, pageSize)},
+		DBProperty{Name: "Свободных страниц", Value: fmt.Sprintf("%d (%s)", freelist, FormatSize(freelist*pageSize))},
+		DBProperty{Name: "WAL", Value: walSize},
+		DBProperty{Name: "Целостность", Value: m.integrityCheck()},
+	)
+	for _, pragma := range sqlitePragmas {
+		var value string
+		if err := m.db.QueryRow("PRAGMA " + pragma).Scan(&value); err != nil {
+			continue
+		}
+		stats.Properties = append(stats.Properties, DBProperty{Name: pragma, Value: value})
+	}
+
+	tables, err := m.GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tables: %w", err)
+	}
+	stats.Tables = tables
+	stats.TableCount = len(tables)
+	stats.SlowQueries = []SlowQuery{}
+
+	return stats, nil
+}
+
+// integrityCheck возвращает результат PRAGMA integrity_check, повторяя его не чаще sqliteIntegrityInterval
+func (m *SQLiteMonitor) integrityCheck() string {
+	if m.integrity != "" && time.Since(m.integrityAt) < sqliteIntegrityInterval {
+		return m.integrity
+	}
+
+	rows, err := m.db.Query("PRAGMA integrity_check(10)")
+	if err != nil {
+		return fmt.Sprintf("ошибка: %v", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err == nil {
+			problems = append(problems, line)
+		}
+	}
+
+	m.integrity = strings.Join(problems, "; ")
+	m.integrityAt = time.Now()
+	return m.integrity
+}
+
+// GetTables получает таблицы с количеством строк, индексов и размером (если доступна dbstat)
+func (m *SQLiteMonitor) GetTables() ([]TableInfo, error) {
+	rows, err := m.db.Query(`
+		SELECT 
+			t.name,
+			(SELECT COUNT(*) FROM sqlite_master i WHERE i.type = 'index' AND i.tbl_name = t.name)
+		FROM sqlite_master t
+		WHERE t.type = 'table' AND t.name NOT LIKE 'sqlite_%'
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+
+	var tables []TableInfo
+	for rows.Next() {
+		var table TableInfo
+		if err := rows.Scan(&table.Name, &table.Indexes); err != nil {
+			continue
+		}
+		table.LastUpdate = time.Now()
+		tables = append(tables, table)
+	}
+	rows.Close()
+
+	for i := range tables {
+		// Локальный файл: COUNT(*) дешевле, чем в серверных СУБД, поэтому считается всегда
+		if count, err := m.CountRows(tables[i]); err == nil {
+			tables[i].Rows = count
+			tables[i].RowsExact = true
+		} else {
+			tables[i].Rows = -1
+		}
+
+		// dbstat есть не во всех сборках SQLite
+		var heap, index int64
+		err := m.db.QueryRow(`
+			SELECT
+				COALESCE(SUM(CASE WHEN name = ? THEN pgsize END), 0),
+				COALESCE(SUM(CASE WHEN name <> ? THEN pgsize END), 0)
+			FROM dbstat 
+			WHERE name = ? OR name IN (SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ?)
+		`, tables[i].Name, tables[i].Name, tables[i].Name, tables[i].Name).Scan(&heap, &index)
+		if err != nil {
+			tables[i].Size = "n/a"
+			continue
+		}
+		tables[i].Sizes = &TableSizes{Total: heap + index, Heap: heap, Index: index, Toast: -1}
+		tables[i].Size = FormatSize(heap + index)
+	}
+
+	return tables, nil
+}
+
+// CountRows считает строки таблицы через COUNT(*)
+func (m *SQLiteMonitor) CountRows(table TableInfo) (int64, error) {
+	var count int64
+	if err := m.db.QueryRow(countRowsQuery("sqlite", table)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return count, nil
+}
+
+// GetSlowQueries в SQLite нет журнала запросов
+func (m *SQLiteMonitor) GetSlowQueries() ([]SlowQuery, error) {
+	return []SlowQuery{}, nil
+}
+
+// ResetSlowQueries в SQLite нечего сбрасывать
+func (m *SQLiteMonitor) ResetSlowQueries() error {
+	return fmt.Errorf("SQLite has no query statistics")
+}
+
+// GetConnections у встроенной БД нет серверных соединений
+func (m *SQLiteMonitor) GetConnections() ([]ConnectionInfo, error) {
+	return []ConnectionInfo{}, nil
+}
+
+// GetLocks блокировки SQLite не видны другим процессам
+func (m *SQLiteMonitor) GetLocks() ([]LockInfo, error) {
+	return []LockInfo{}, nil
+}
+
+// GetReplication у SQLite нет встроенной репликации
+func (m *SQLiteMonitor) GetReplication() (*ReplicationStatus, error) {
+	return &ReplicationStatus{}, nil
+}
+
+// GetIndexes получает индексы из sqlite_master и PRAGMA index_list/index_info.
+// SQLite не считает обращения к индексам, поэтому ищутся только дубликаты и перекрытия
+func (m *SQLiteMonitor) GetIndexes() (*IndexReport, error) {
+	rows, err := m.db.Query(sqliteIndexesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+
+	var indexes []IndexInfo
+	for rows.Next() {
+		var index IndexInfo
+		var columns string
+		var partial bool
+		if err := rows.Scan(&index.Table, &index.Name, &index.Unique, &index.Primary, &partial,
+			&index.Definition, &columns); err != nil {
+			continue
+		}
+		index.Method = "btree"
+		index.Columns = strings.Split(columns, "\n")
+		index.Scans = -1
+		index.Valid = true
+		if partial {
+			index.Predicate = index.Definition
+		}
+		if index.Definition == "" {
+			index.Definition = "(индекс ограничения PRIMARY KEY/UNIQUE)"
+		}
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+
+	// dbstat есть не во всех сборках SQLite
+	for i := range indexes {
+		m.db.QueryRow("SELECT COALESCE(SUM(pgsize), 0) FROM dbstat WHERE name = ?", indexes[i].Name).
+			Scan(&indexes[i].SizeBytes)
+	}
+
+	return buildIndexReport("sqlite", indexes, nil), nil
+}
+
+// ListSchemas выбор схемы для SQLite не поддерживается
+func (m *SQLiteMonitor) ListSchemas() ([]string, error) {
+	return nil, fmt.Errorf("schema selection is not supported for SQLite")
+}
+
+// Explain планы запросов показываются только для PostgreSQL и MySQL
+func (m *SQLiteMonitor) Explain(query string, analyze bool) (*ExplainPlan, error) {
+	return nil, fmt.Errorf("EXPLAIN is not supported for SQLite")
+}
+
+// KillConnection не поддерживается для SQLite
+func (m *SQLiteMonitor) KillConnection(connectionID string) error {
+	return fmt.Errorf("SQLite has no connections to kill")
+}
+
+// Close закрывает соединение с БД
+func (m *SQLiteMonitor) Close() error {
+	if m.db != nil {
+		return m.db.Close()
+	}
+	return nil
+}
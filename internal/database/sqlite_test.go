@@ -0,0 +1,128 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// newTestSQLite создает файл БД во временном каталоге, выполняет schema и подключает к нему монитор
+func newTestSQLite(t *testing.T, schema ...string) *SQLiteMonitor {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, statement := range schema {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	db.Close()
+
+	monitor := NewSQLiteMonitor()
+	if err := monitor.Connect("sqlite://" + path); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { monitor.Close() })
+	return monitor
+}
+
+func TestSQLiteConnectMissingFile(t *testing.T) {
+	monitor := NewSQLiteMonitor()
+	if err := monitor.Connect("sqlite://" + filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Fatal("Connect created a new database instead of failing on a missing file")
+	}
+}
+
+func TestSQLiteGetTables(t *testing.T) {
+	monitor := newTestSQLite(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT)",
+		"CREATE UNIQUE INDEX users_email ON users (email)",
+		"CREATE INDEX users_email_id ON users (email, id)",
+		"INSERT INTO users (email) VALUES ('a@example.com'), ('b@example.com'), ('c@example.com')",
+		"CREATE TABLE empty (id INTEGER)",
+		`CREATE TABLE "order" (id INTEGER)`,
+		`INSERT INTO "order" VALUES (1)`,
+	)
+
+	tables, err := monitor.GetTables()
+	if err != nil {
+		t.Fatalf("GetTables: %v", err)
+	}
+	if len(tables) != 3 {
+		t.Fatalf("GetTables returned %d tables, want 3: %+v", len(tables), tables)
+	}
+
+	tests := []struct {
+		name    string
+		rows    int64
+		indexes int
+	}{
+		{"empty", 0, 0},
+		{"order", 1, 0}, // зарезервированное слово: COUNT(*) без кавычек не выполнится
+		{"users", 3, 2},
+	}
+	for i, tt := range tests {
+		table := tables[i]
+		if table.Name != tt.name {
+			t.Errorf("tables[%d].Name = %q, want %q", i, table.Name, tt.name)
+			continue
+		}
+		if table.Rows != tt.rows || !table.RowsExact {
+			t.Errorf("%s: Rows = %d (exact %v), want exact %d", tt.name, table.Rows, table.RowsExact, tt.rows)
+		}
+		if table.Indexes != tt.indexes {
+			t.Errorf("%s: Indexes = %d, want %d", tt.name, table.Indexes, tt.indexes)
+		}
+
+		count, err := monitor.CountRows(table)
+		if err != nil {
+			t.Fatalf("CountRows(%s): %v", tt.name, err)
+		}
+		if count != tt.rows {
+			t.Errorf("CountRows(%s) = %d, want %d", tt.name, count, tt.rows)
+		}
+	}
+
+	// Сборка modernc включает dbstat: размеры по страницам известны
+	users := tables[2]
+	if users.Sizes == nil {
+		t.Fatalf("users: Sizes = nil, Size = %q; dbstat is expected in modernc sqlite", users.Size)
+	}
+	if users.Sizes.Heap <= 0 || users.Sizes.Index <= 0 || users.Sizes.Total != users.Sizes.Heap+users.Sizes.Index {
+		t.Errorf("users: Sizes = %+v", *users.Sizes)
+	}
+	if users.Size != FormatSize(users.Sizes.Total) {
+		t.Errorf("users: Size = %q, want %q", users.Size, FormatSize(users.Sizes.Total))
+	}
+}
+
+func TestSQLiteGetTablesWithoutDbstat(t *testing.T) {
+	// Обычная таблица dbstat перекрывает одноименную виртуальную, и запрос размеров падает так же,
+	// как в сборке SQLite без dbstat
+	monitor := newTestSQLite(t,
+		"CREATE TABLE dbstat (id INTEGER)",
+		"CREATE TABLE items (id INTEGER)",
+		"INSERT INTO items VALUES (1), (2)",
+	)
+
+	tables, err := monitor.GetTables()
+	if err != nil {
+		t.Fatalf("GetTables: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("GetTables returned %d tables, want 2: %+v", len(tables), tables)
+	}
+
+	for _, table := range tables {
+		if table.Size != "n/a" || table.Sizes != nil {
+			t.Errorf("%s: Size = %q, Sizes = %+v; want n/a without sizes", table.Name, table.Size, table.Sizes)
+		}
+	}
+	if items := tables[1]; items.Rows != 2 || !items.RowsExact {
+		t.Errorf("items: Rows = %d (exact %v), want exact 2", items.Rows, items.RowsExact)
+	}
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TableSizes размер таблицы по частям в байтах
+type TableSizes struct {
+	Total int64
+	Heap  int64 // данные таблицы (для MongoDB - storageSize коллекции)
+	Index int64
+	Toast int64 // TOAST PostgreSQL; -1 для остальных СУБД
+}
+
+// Parts размер по частям: "данные 1.00 MB, индексы 200.00 kB, TOAST 0 bytes"
+func (s *TableSizes) Parts() string {
+	parts := fmt.Sprintf("данные %s, индексы %s", FormatSize(s.Heap), FormatSize(s.Index))
+	if s.Toast >= 0 {
+		parts += ", TOAST " + FormatSize(s.Toast)
+	}
+	return parts
+}
+
+// FormatRows количество строк таблицы: точное целиком, оценка из статистики - "~1.2M", неизвестное - "-"
+func FormatRows(table TableInfo) string {
+	switch {
+	case table.Rows < 0:
+		return "-"
+	case table.RowsExact:
+		return strconv.FormatInt(table.Rows, 10)
+	default:
+		return "~" + FormatCount(table.Rows)
+	}
+}
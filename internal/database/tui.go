@@ -1,34 +1,100 @@
 package database
 
 import (
+	"cmp"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
+	"uno/internal/alerts"
+	"uno/internal/table"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// historySize количество тиков, хранимых для спарклайнов в обзоре
+const historySize = 30
+
 type tickMsg time.Time
 
+// Вкладки мониторинга БД
+const (
+	tabOverview = iota
+	tabTables
+	tabSlowQueries
+	tabConnections
+	tabLocks
+	tabReplication
+	tabIndexes
+)
+
+// lockRow строка дерева блокировок в плоском виде
+type lockRow struct {
+	node  *LockNode
+	depth int
+}
+
 type DBModel struct {
 	monitor      DBMonitor
 	stats        *DBStats
+	connections  []ConnectionInfo
+	locks        []lockRow
+	connErr      error // ошибки соединений и блокировок показываются только на своих вкладках
+	lockErr      error
+	killErr      error // последняя неудачная попытка завершить соединение
+	replication  *ReplicationStatus
+	replErr      error // ошибка репликации не мешает остальным вкладкам
+	slowErr      error // ошибка сброса статистики запросов
+	indexes      *IndexReport
+	indexErr     error
+	qpsHistory   []float64
+	latHistory   []float64
 	err          error
 	ready        bool
 	selectedTab  int
 	tabs         []string
+	cursor       int
+	confirmKill  bool
 	connectionID string
+	alerts       *alerts.Engine
+
+	// Просмотр плана медленного запроса
+	showPlan       bool
+	confirmAnalyze bool
+	confirmReset   bool
+	plan           *ExplainPlan
+	planErr        error
+	planCursor     int
+	planCollapsed  map[*PlanNode]bool
+
+	// Вкладка таблиц: выбор схемы PostgreSQL или базы MySQL, рост и точные COUNT(*)
+	showSchemas  bool
+	schemas      []string // первые два варианта - схема по умолчанию и AllSchemas
+	schemaCursor int
+	tableErr     error
+	growth       *GrowthTracker
+	exactRows    map[string]int64 // точные количества строк по QualifiedName, переживают обновления
+	sortByGrowth bool
+	healthView   bool // vacuum и раздувание PostgreSQL вместо размеров
 }
 
 func NewDBModel(monitor DBMonitor) *DBModel {
 	return &DBModel{
 		monitor:     monitor,
-		tabs:        []string{"Обзор", "Таблицы", "Медленные запросы"},
-		selectedTab: 0,
+		tabs:        []string{"Обзор", "Таблицы", "Медленные запросы", "Соединения", "Блокировки", "Репликация", "Индексы"},
+		selectedTab: tabOverview,
+		growth:      NewGrowthTracker(DefaultGrowthWindow),
+		exactRows:   make(map[string]int64),
 	}
 }
 
+// SetAlerts включает проверку правил алертинга на каждом тике
+func (m *DBModel) SetAlerts(engine *alerts.Engine) {
+	m.alerts = engine
+}
+
 func (m *DBModel) Init() tea.Cmd {
 	return tick()
 }
@@ -36,18 +102,118 @@ func (m *DBModel) Init() tea.Cmd {
 func (m *DBModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Подтверждение завершения соединения перехватывает все клавиши
+		if m.confirmKill {
+			switch msg.String() {
+			case "y", "Y", "enter":
+				m.killErr = m.monitor.KillConnection(m.connectionID)
+				m.refreshConnections()
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+			m.confirmKill = false
+			m.connectionID = ""
+			return m, nil
+		}
+
+		// EXPLAIN ANALYZE выполняет запрос, поэтому запускается только после подтверждения
+		if m.confirmAnalyze {
+			switch msg.String() {
+			case "y", "Y", "enter":
+				m.explainSelected(true)
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+			m.confirmAnalyze = false
+			return m, nil
+		}
+
+		if m.confirmReset {
+			switch msg.String() {
+			case "y", "Y", "enter":
+				if err := m.monitor.ResetSlowQueries(); err != nil {
+					m.slowErr = err
+				} else {
+					m.refreshSlowQueries()
+				}
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+			m.confirmReset = false
+			return m, nil
+		}
+
+		if m.showPlan {
+			return m.updatePlan(msg)
+		}
+
+		if m.showSchemas {
+			return m.updateSchemas(msg)
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c", "esc":
 			return m, tea.Quit
 		case "tab":
 			m.selectedTab = (m.selectedTab + 1) % len(m.tabs)
+			m.cursor = 0
+			m.refreshIndexes()
 		case "shift+tab":
 			m.selectedTab = (m.selectedTab - 1 + len(m.tabs)) % len(m.tabs)
+			m.cursor = 0
+			m.refreshIndexes()
+		case "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down":
+			if m.cursor < m.listLen()-1 {
+				m.cursor++
+			}
 		case "k":
-			if m.connectionID != "" {
-				if err := m.monitor.KillConnection(m.connectionID); err != nil {
-					m.err = err
+			if id := m.selectedID(); id != "" {
+				m.connectionID = id
+				m.confirmKill = true
+			}
+		case "e":
+			if m.selectedSlowQuery() != nil {
+				m.explainSelected(false)
+			}
+		case "a":
+			if m.selectedSlowQuery() != nil {
+				m.confirmAnalyze = true
+			}
+		case "s":
+			if m.selectedTab == tabTables {
+				m.openSchemas()
+			}
+			if m.selectedTab == tabSlowQueries {
+				opts := m.monitor.SlowQueryOptions()
+				if opts.SortBy == SlowQuerySortTotal {
+					opts.SortBy = SlowQuerySortMean
+				} else {
+					opts.SortBy = SlowQuerySortTotal
 				}
+				m.monitor.SetSlowQueryOptions(opts)
+				m.cursor = 0
+				m.refreshSlowQueries()
+			}
+		case "r":
+			if m.selectedTab == tabSlowQueries {
+				m.confirmReset = true
+			}
+		case "c":
+			if table := m.selectedTable(); table != nil {
+				m.countSelected(*table)
+			}
+		case "g":
+			if m.selectedTab == tabTables {
+				m.sortByGrowth = !m.sortByGrowth
+				m.cursor = 0
+			}
+		case "v":
+			if m.selectedTab == tabTables && m.hasTableHealth() {
+				m.healthView = !m.healthView
 			}
 		}
 	case tickMsg:
@@ -61,13 +227,110 @@ func (m *DBModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.stats = stats
 			m.err = nil
+			m.trackTables()
+			m.qpsHistory = appendHistory(m.qpsHistory, stats.QueriesPerSecond)
+			m.latHistory = appendHistory(m.latHistory, float64(stats.AvgResponseTime)/float64(time.Millisecond))
 		}
 
+		m.refreshConnections()
+		m.replication, m.replErr = m.monitor.GetReplication()
+		m.refreshIndexes()
+		m.evaluateAlerts()
+
 		return m, tick()
 	}
 	return m, nil
 }
 
+// refreshConnections перечитывает соединения и блокировки и удерживает курсор в пределах списка
+func (m *DBModel) refreshConnections() {
+	connections, err := m.monitor.GetConnections()
+	m.connErr = err
+	if err == nil {
+		m.connections = connections
+	}
+
+	// Представления блокировок есть не везде (MariaDB, MySQL без sys и data_lock_waits)
+	// и могут быть недоступны роли - остальные вкладки от этого не страдают
+	locks, err := m.monitor.GetLocks()
+	m.lockErr = err
+	m.locks = nil
+	if err == nil {
+		WalkBlockingTree(BuildBlockingTree(locks), func(node *LockNode, depth int) {
+			m.locks = append(m.locks, lockRow{node: node, depth: depth})
+		})
+	}
+
+	if m.cursor >= m.listLen() {
+		m.cursor = m.listLen() - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// refreshIndexes перечитывает анализ индексов, только пока открыта вкладка: запрос тяжелее остальных
+func (m *DBModel) refreshIndexes() {
+	if m.selectedTab != tabIndexes {
+		return
+	}
+	m.indexes, m.indexErr = m.monitor.GetIndexes()
+}
+
+// evaluateAlerts проверяет правила на свежей статистике и числе заблокированных сессий
+func (m *DBModel) evaluateAlerts() {
+	if m.alerts == nil || m.stats == nil {
+		return
+	}
+
+	metrics := m.stats.Metrics()
+	blocked := 0
+	for _, row := range m.locks {
+		if row.depth > 0 {
+			blocked++
+		}
+	}
+	metrics["db.blocked_sessions"] = float64(blocked)
+	for name, value := range m.replication.Metrics() {
+		metrics[name] = value
+	}
+
+	m.alerts.Evaluate(metrics)
+}
+
+// listLen возвращает количество выбираемых строк на текущей вкладке
+func (m *DBModel) listLen() int {
+	switch m.selectedTab {
+	case tabSlowQueries:
+		if m.stats != nil {
+			return len(m.stats.SlowQueries)
+		}
+	case tabTables:
+		if m.stats != nil {
+			return len(m.stats.Tables)
+		}
+	case tabConnections:
+		return len(m.connections)
+	case tabLocks:
+		return len(m.locks)
+	}
+	return 0
+}
+
+// selectedID возвращает ID соединения под курсором на текущей вкладке
+func (m *DBModel) selectedID() string {
+	if m.cursor >= m.listLen() {
+		return ""
+	}
+	switch m.selectedTab {
+	case tabConnections:
+		return m.connections[m.cursor].ID
+	case tabLocks:
+		return m.locks[m.cursor].node.Lock.ID
+	}
+	return ""
+}
+
 func (m *DBModel) View() string {
 	if !m.ready {
 		return "Подключение к базе данных..."
@@ -89,25 +352,87 @@ func (m *DBModel) View() string {
 		Bold(true).
 		Render("🗄️  Мониторинг базы данных")
 	sb.WriteString(title + "\n\n")
+	sb.WriteString(m.alerts.Banner())
 
 	// Табы
 	tabs := m.renderTabs()
 	sb.WriteString(tabs + "\n\n")
 
+	if m.showPlan {
+		sb.WriteString(m.renderPlan())
+		help := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#626262")).
+			Render("↑/↓: выбор узла | Enter/Space: свернуть/развернуть | Esc: к запросам | ctrl+c: выход")
+		sb.WriteString("\n\n" + help)
+		return sb.String()
+	}
+
+	if m.showSchemas {
+		sb.WriteString(m.renderSchemas())
+		help := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#626262")).
+			Render("↑/↓: выбор | Enter: показать таблицы | Esc: отмена | ctrl+c: выход")
+		sb.WriteString("\n\n" + help)
+		return sb.String()
+	}
+
 	// Контент в зависимости от выбранной вкладки
 	switch m.selectedTab {
-	case 0:
+	case tabOverview:
 		sb.WriteString(m.renderOverview())
-	case 1:
+	case tabTables:
 		sb.WriteString(m.renderTables())
-	case 2:
+	case tabSlowQueries:
 		sb.WriteString(m.renderSlowQueries())
+	case tabConnections:
+		sb.WriteString(m.renderConnections())
+	case tabLocks:
+		sb.WriteString(m.renderLocks())
+	case tabReplication:
+		sb.WriteString(m.renderReplication())
+	case tabIndexes:
+		sb.WriteString(m.renderIndexes())
+	}
+
+	// Подтверждение завершения соединения
+	if m.confirmKill {
+		confirm := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFAA00")).
+			Bold(true).
+			Render(fmt.Sprintf("Завершить соединение %s? (y/n)", m.connectionID))
+		sb.WriteString("\n" + confirm)
+	}
+
+	if m.confirmReset {
+		confirm := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFAA00")).
+			Bold(true).
+			Render("Сбросить накопленную статистику запросов на сервере? (y/n)")
+		sb.WriteString("\n" + confirm)
+	}
+
+	if m.confirmAnalyze {
+		confirm := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFAA00")).
+			Bold(true).
+			Render("Выполнить запрос с EXPLAIN ANALYZE? Изменения будут откачены (y/n)")
+		sb.WriteString("\n" + confirm)
 	}
 
 	// Помощь
+	helpText := "Tab: переключение вкладок | ↑/↓: выбор | q: выход | k: завершить соединение"
+	if m.selectedTab == tabTables {
+		helpText = "Tab: переключение вкладок | ↑/↓: выбор | q: выход | s: выбор схемы | c: COUNT(*) | g: сортировка по росту"
+		if m.hasTableHealth() {
+			helpText += " | v: размеры/vacuum"
+		}
+	}
+	if m.selectedTab == tabSlowQueries {
+		helpText = "Tab: переключение вкладок | ↑/↓: выбор | q: выход | e: EXPLAIN | a: EXPLAIN ANALYZE | s: сортировка | r: сброс статистики"
+	}
 	help := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#626262")).
-		Render("Tab: переключение вкладок | q: выход | k: завершить соединение")
+		Render(helpText)
 	sb.WriteString("\n\n" + help)
 
 	return sb.String()
@@ -135,65 +460,756 @@ func (m *DBModel) renderOverview() string {
 
 	data := [][]string{
 		{"Активные соединения", fmt.Sprintf("%d / %d", m.stats.ActiveConnections, m.stats.MaxConnections)},
+		{"Запросов/сек", fmt.Sprintf("%-10.1f %s", m.stats.QueriesPerSecond, table.Sparkline(m.qpsHistory, historySize))},
+		{"Среднее время", fmt.Sprintf("%-10s %s", m.stats.AvgResponseTime.Round(time.Microsecond), table.Sparkline(m.latHistory, historySize))},
+		{"Ошибки/откаты", fmt.Sprintf("%d", m.stats.ErrorCount)},
 		{"Размер БД", m.stats.DatabaseSize},
 		{"Количество таблиц", fmt.Sprintf("%d", m.stats.TableCount)},
 		{"Обновлено", m.stats.LastUpdate.Format("15:04:05")},
 	}
 
+	for _, property := range m.stats.Properties {
+		data = append(data, []string{property.Name, property.Value})
+	}
+
 	var sb strings.Builder
 	for _, row := range data {
 		sb.WriteString(fmt.Sprintf("%-20s: %s\n", row[0], row[1]))
 	}
 
-	// Прогресс соединений
-	connectionPercent := float64(m.stats.ActiveConnections) / float64(m.stats.MaxConnections)
-	progressBar := m.renderProgressBar(connectionPercent, 30)
-	sb.WriteString(fmt.Sprintf("\nИспользование соединений: %s %.1f%%\n",
-		progressBar, connectionPercent*100))
+	// Прогресс соединений; у встроенных БД лимита соединений нет
+	if m.stats.MaxConnections > 0 {
+		connectionPercent := float64(m.stats.ActiveConnections) / float64(m.stats.MaxConnections)
+		progressBar := m.renderProgressBar(connectionPercent, 30)
+		sb.WriteString(fmt.Sprintf("\nИспользование соединений: %s %.1f%%\n",
+			progressBar, connectionPercent*100))
+	}
 
 	return sb.String()
 }
 
+// appendHistory добавляет значение в историю, ограничивая ее длину historySize
+func appendHistory(history []float64, value float64) []float64 {
+	history = append(history, value)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	return history
+}
+
 func (m *DBModel) renderTables() string {
+	var sb strings.Builder
+	info := "Схема: " + schemaLabel(m.monitor.Schema())
+	if m.sortByGrowth {
+		info += " | Сортировка: по росту"
+	}
+	if !m.healthView {
+		info += fmt.Sprintf(" | Рост за последние %.0f мин", DefaultGrowthWindow.Minutes())
+	}
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#626262")).
+		Render(info) + "\n")
+	if m.tableErr != nil {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(m.tableErr.Error()) + "\n")
+	}
+	sb.WriteString("\n")
+
 	if m.stats == nil || len(m.stats.Tables) == 0 {
-		return "Нет таблиц"
+		sb.WriteString("Нет таблиц")
+		return sb.String()
+	}
+
+	tables := m.displayedTables()
+	if m.healthView && m.hasTableHealth() {
+		sb.WriteString(m.renderTableHealth(tables))
+		return sb.String()
 	}
 
-	var sb strings.Builder
 	sb.WriteString("Таблицы:\n\n")
 
-	// Заголовок таблицы
-	header := fmt.Sprintf("%-20s %-10s %-15s %-10s", "Имя", "Строк", "Размер", "Индексы")
+	// Заголовок таблицы; отступ под маркер курсора
+	header := fmt.Sprintf("  %-30s %-10s %-11s %-11s %-11s %-11s %-14s %-5s",
+		"Имя", "Строк", "Размер", "Данные", "Индексы", "TOAST", "Рост", "Инд.")
 	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(header) + "\n")
-	sb.WriteString(strings.Repeat("-", len(header)) + "\n")
+	sb.WriteString(strings.Repeat("-", lipgloss.Width(header)) + "\n")
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#FF6B6B"))
+	riskStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true)
 
 	// Данные таблиц
-	for _, table := range m.stats.Tables {
-		row := fmt.Sprintf("%-20s %-15s %-10d",
-			table.Name, table.Size, table.Indexes)
-		sb.WriteString(row + "\n")
+	for i, table := range tables {
+		heap, index, toast := "n/a", "n/a", "n/a"
+		if sizes := table.Sizes; sizes != nil {
+			heap, index, toast = FormatSize(sizes.Heap), FormatSize(sizes.Index), "-"
+			if sizes.Toast >= 0 {
+				toast = FormatSize(sizes.Toast)
+			}
+		}
+		row := fmt.Sprintf("%-30s %-10s %-11s %-11s %-11s %-11s %-14s %-5d",
+			truncate(table.QualifiedName(), 30), FormatRows(table), table.Size,
+			heap, index, toast, FormatGrowth(table.Growth), table.Indexes)
+
+		switch {
+		case i == m.cursor:
+			sb.WriteString(selectedStyle.Render("> "+row) + "\n")
+		case table.Health != nil && len(table.Health.Risks()) > 0:
+			sb.WriteString(riskStyle.Render("  "+row) + "\n")
+		default:
+			sb.WriteString("  " + row + "\n")
+		}
+	}
+
+	if slices.ContainsFunc(tables, func(t TableInfo) bool { return t.Rows >= 0 && !t.RowsExact }) {
+		sb.WriteString("\n" + lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#626262")).
+			Render("~ - оценка из статистики, c - точный COUNT(*) выбранной таблицы") + "\n")
+	}
+
+	return sb.String()
+}
+
+// renderTableHealth таблицы PostgreSQL с мертвыми строками, раздуванием, vacuum и возрастом XID
+func (m *DBModel) renderTableHealth(tables []TableInfo) string {
+	var sb strings.Builder
+	sb.WriteString("Таблицы:\n\n")
+
+	riskStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true)
+	reasonStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAA00"))
+
+	header := fmt.Sprintf("%-30s %-10s %-8s %-8s %-7s %-7s %-12s %-12s %-8s",
+		"Имя", "Размер", "Живых", "Мертвых", "Мертв%", "Bloat", "Vacuum", "Analyze", "XID age")
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(header) + "\n")
+	sb.WriteString(strings.Repeat("-", lipgloss.Width(header)) + "\n")
+
+	var vacuums []string
+	for _, table := range tables {
+		health := table.Health
+		if health == nil {
+			sb.WriteString(fmt.Sprintf("%-30s %-10s\n", truncate(table.QualifiedName(), 30), table.Size))
+			continue
+		}
+
+		bloat := "n/a"
+		if health.BloatPercent >= 0 {
+			bloat = fmt.Sprintf("%.0f%%", health.BloatPercent)
+		}
+		row := fmt.Sprintf("%-30s %-10s %-8s %-8s %-7s %-7s %-12s %-12s %-8s",
+			truncate(table.QualifiedName(), 30),
+			table.Size,
+			FormatCount(health.LiveTuples),
+			FormatCount(health.DeadTuples),
+			fmt.Sprintf("%.1f%%", health.DeadPercent()),
+			bloat,
+			FormatSince(health.LastVacuumed()),
+			FormatSince(health.LastAnalyzed()),
+			FormatCount(health.XIDAge))
+
+		if risks := health.Risks(); len(risks) > 0 {
+			sb.WriteString(riskStyle.Render(row) + "\n")
+			sb.WriteString(reasonStyle.Render("    ⚠ "+strings.Join(risks, ", ")) + "\n")
+		} else {
+			sb.WriteString(row + "\n")
+		}
+
+		if vacuum := health.Vacuum; vacuum != nil {
+			vacuums = append(vacuums, fmt.Sprintf("%-30s pid %-8d %-28s %5.1f%% (%d/%d стр.), проходов по индексам: %d",
+				truncate(table.QualifiedName(), 30), vacuum.PID, vacuum.Phase, vacuum.Percent(),
+				vacuum.HeapBlksScanned, vacuum.HeapBlksTotal, vacuum.IndexVacuumCount))
+		}
+	}
+
+	if len(vacuums) > 0 {
+		sb.WriteString("\nВыполняется VACUUM:\n\n")
+		for _, vacuum := range vacuums {
+			sb.WriteString(vacuum + "\n")
+		}
 	}
 
 	return sb.String()
 }
 
 func (m *DBModel) renderSlowQueries() string {
+	var sb strings.Builder
+
+	opts := m.monitor.SlowQueryOptions()
+	sortBy := "по среднему времени"
+	if opts.SortBy == SlowQuerySortTotal {
+		sortBy = "по суммарному времени"
+	}
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#626262")).
+		Render(fmt.Sprintf("Порог: %v | Сортировка: %s | Показано до %d", opts.Threshold, sortBy, opts.Limit)) + "\n")
+	if m.slowErr != nil {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(m.slowErr.Error()) + "\n")
+	}
+	sb.WriteString("\n")
+
 	if m.stats == nil || len(m.stats.SlowQueries) == 0 {
-		return "Нет медленных запросов"
+		sb.WriteString("Нет медленных запросов")
+		return sb.String()
 	}
 
-	var sb strings.Builder
 	sb.WriteString("Медленные запросы:\n\n")
 
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#FF6B6B"))
+
 	for i, query := range m.stats.SlowQueries {
-		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, query.Query))
-		sb.WriteString(fmt.Sprintf("   Время: %v | Пользователь: %s\n\n",
-			query.Duration, query.User))
+		line := fmt.Sprintf("%d. %s", i+1, truncate(strings.Join(strings.Fields(query.Query), " "), 100))
+		if i == m.cursor {
+			sb.WriteString(selectedStyle.Render("> "+line) + "\n")
+		} else {
+			sb.WriteString("  " + line + "\n")
+		}
+		for _, detail := range query.Details() {
+			sb.WriteString("     " + detail + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func (m *DBModel) renderConnections() string {
+	if m.connErr != nil {
+		return fmt.Sprintf("Не удалось получить соединения: %v", m.connErr)
+	}
+	if len(m.connections) == 0 {
+		return "Нет соединений"
+	}
+
+	var sb strings.Builder
+	if m.killErr != nil {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(m.killErr.Error()) + "\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("Соединения (%d):\n\n", len(m.connections)))
+
+	// Заголовок таблицы
+	header := fmt.Sprintf("  %-8s %-12s %-16s %-20s %-8s %-18s %s",
+		"ID", "Пользователь", "Клиент", "Состояние", "Время", "Ожидание", "Запрос")
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(header) + "\n")
+	sb.WriteString(strings.Repeat("-", len(header)) + "\n")
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#FF6B6B"))
+
+	// Данные соединений
+	for i, conn := range m.connections {
+		row := fmt.Sprintf("%-8s %-12s %-16s %-20s %-8s %-18s %s",
+			truncate(conn.ID, 8),
+			truncate(conn.User, 12),
+			truncate(conn.ClientAddr, 16),
+			truncate(conn.State, 20),
+			conn.QueryAge.Truncate(time.Second).String(),
+			truncate(conn.WaitEvent, 18),
+			truncate(strings.Join(strings.Fields(conn.Query), " "), 50))
+
+		if i == m.cursor {
+			sb.WriteString(selectedStyle.Render("> "+row) + "\n")
+		} else {
+			sb.WriteString("  " + row + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func (m *DBModel) renderLocks() string {
+	if m.lockErr != nil {
+		return fmt.Sprintf("Не удалось получить блокировки: %v", m.lockErr)
+	}
+	if len(m.locks) == 0 {
+		return "Блокировок нет"
+	}
+
+	var sb strings.Builder
+	if m.killErr != nil {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(m.killErr.Error()) + "\n\n")
+	}
+	sb.WriteString("Цепочки блокировок (корневые блокирующие сессии выделены):\n\n")
+
+	rootStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FF0000")).
+		Bold(true)
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#FF6B6B"))
+
+	for i, row := range m.locks {
+		lock := row.node.Lock
+
+		prefix := strings.Repeat("   ", row.depth)
+		if row.depth > 0 {
+			prefix += "└─ "
+		}
+
+		line := fmt.Sprintf("%s%s %s", prefix, lock.ID, lock.User)
+		if lock.Mode != "" || lock.Relation != "" {
+			line += fmt.Sprintf(" | ждет %s %s", lock.Mode, lock.Relation)
+		}
+		if lock.WaitDuration > 0 {
+			line += fmt.Sprintf(" | %v", lock.WaitDuration)
+		}
+		if row.depth == 0 && len(row.node.Children) > 0 {
+			line += fmt.Sprintf(" | блокирует: %d", len(row.node.Children))
+		}
+		line += " | " + truncate(strings.Join(strings.Fields(lock.Query), " "), 50)
+
+		switch {
+		case i == m.cursor:
+			sb.WriteString(selectedStyle.Render("> "+line) + "\n")
+		case row.depth == 0:
+			sb.WriteString(rootStyle.Render("  "+line) + "\n")
+		default:
+			sb.WriteString("  " + line + "\n")
+		}
 	}
 
 	return sb.String()
 }
 
+func (m *DBModel) renderReplication() string {
+	if m.replErr != nil {
+		return fmt.Sprintf("Не удалось получить состояние репликации: %v", m.replErr)
+	}
+	if m.replication == nil || m.replication.Role == "" {
+		return "Репликация не настроена"
+	}
+
+	status := m.replication
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Роль: %s\n\n", lipgloss.NewStyle().Bold(true).Render(status.Role)))
+
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true)
+
+	if upstream := status.Upstream; upstream != nil {
+		sb.WriteString("Источник:\n")
+		sb.WriteString(fmt.Sprintf("  %-18s: %s\n", "Хост", upstream.Source))
+		sb.WriteString(fmt.Sprintf("  %-18s: %s\n", "IO поток", upstream.IOState))
+		if upstream.SQLState != "" {
+			sb.WriteString(fmt.Sprintf("  %-18s: %s\n", "SQL поток", upstream.SQLState))
+		}
+		sb.WriteString(fmt.Sprintf("  %-18s: %s\n", "Получено", upstream.ReceivePos))
+		sb.WriteString(fmt.Sprintf("  %-18s: %s\n", "Применено", upstream.ReplayPos))
+		if upstream.LagBytes > 0 {
+			sb.WriteString(fmt.Sprintf("  %-18s: %s\n", "Не применено", FormatSize(upstream.LagBytes)))
+		}
+		sb.WriteString(fmt.Sprintf("  %-18s: %s\n", "Задержка", FormatLag(upstream.Delay)))
+		if upstream.LastError != "" {
+			sb.WriteString(warnStyle.Render(fmt.Sprintf("  %-18s: %s", "Ошибка", upstream.LastError)) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(status.Replicas) > 0 {
+		sb.WriteString(fmt.Sprintf("Реплики (%d):\n\n", len(status.Replicas)))
+		header := fmt.Sprintf("%-16s %-16s %-10s %-6s %-10s %-8s %-8s %-8s %s",
+			"Имя", "Клиент", "Состояние", "Sync", "Отправка", "Write", "Flush", "Replay", "Отставание")
+		sb.WriteString(lipgloss.NewStyle().Bold(true).Render(header) + "\n")
+		sb.WriteString(strings.Repeat("-", len(header)) + "\n")
+
+		for _, replica := range status.Replicas {
+			sb.WriteString(fmt.Sprintf("%-16s %-16s %-10s %-6s %-10s %-8s %-8s %-8s %s\n",
+				truncate(replica.Name, 16),
+				truncate(replica.Client, 16),
+				truncate(replica.State, 10),
+				truncate(replica.SyncState, 6),
+				FormatLagBytes(replica.SentLagBytes),
+				FormatLag(replica.WriteLag),
+				FormatLag(replica.FlushLag),
+				FormatLag(replica.ReplayLag),
+				FormatLagBytes(replica.ReplayLagBytes)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(status.Slots) > 0 {
+		sb.WriteString(fmt.Sprintf("Слоты репликации (%d):\n\n", len(status.Slots)))
+		header := fmt.Sprintf("%-24s %-10s %-8s %s", "Слот", "Тип", "Активен", "Удерживает WAL")
+		sb.WriteString(lipgloss.NewStyle().Bold(true).Render(header) + "\n")
+		sb.WriteString(strings.Repeat("-", len(header)) + "\n")
+
+		for _, slot := range status.Slots {
+			row := fmt.Sprintf("%-24s %-10s %-8t %s",
+				truncate(slot.Name, 24), slot.Type, slot.Active, FormatSize(slot.RetainedBytes))
+			// Неактивный слот удерживает WAL бесконечно
+			if !slot.Active {
+				row = warnStyle.Render(row)
+			}
+			sb.WriteString(row + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func (m *DBModel) renderIndexes() string {
+	if m.indexErr != nil {
+		return fmt.Sprintf("Не удалось проанализировать индексы: %v", m.indexErr)
+	}
+	if m.indexes == nil {
+		return "Анализ индексов..."
+	}
+	if len(m.indexes.Indexes) == 0 && len(m.indexes.Findings) == 0 {
+		return "Нет индексов"
+	}
+
+	var sb strings.Builder
+
+	findingStyles := map[string]lipgloss.Style{
+		IndexFindingInvalid:     lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true),
+		IndexFindingDuplicate:   lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAA00")).Bold(true),
+		IndexFindingOverlapping: lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAA00")),
+		IndexFindingUnused:      lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700")),
+		IndexFindingMissing:     lipgloss.NewStyle().Foreground(lipgloss.Color("#00BFFF")),
+	}
+	suggestionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+
+	if len(m.indexes.Findings) > 0 {
+		sb.WriteString(fmt.Sprintf("Проблемы (%d):\n\n", len(m.indexes.Findings)))
+		for _, finding := range m.indexes.Findings {
+			target := finding.Table
+			if finding.Index != "" {
+				target = finding.Index + " на " + finding.Table
+			}
+			sb.WriteString(findingStyles[finding.Kind].Render(fmt.Sprintf("[%s] %s", finding.Kind, target)))
+			sb.WriteString(" - " + finding.Detail + "\n")
+			sb.WriteString(suggestionStyle.Render("    "+finding.Suggestion) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("Индексы (%d):\n\n", len(m.indexes.Indexes)))
+	header := fmt.Sprintf("%-20s %-24s %-10s %-8s %s", "Таблица", "Индекс", "Размер", "Сканов", "Определение")
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(header) + "\n")
+	sb.WriteString(strings.Repeat("-", len(header)) + "\n")
+
+	for _, index := range m.indexes.Indexes {
+		scans := "n/a"
+		if index.Scans >= 0 {
+			scans = fmt.Sprintf("%d", index.Scans)
+		}
+		sb.WriteString(fmt.Sprintf("%-20s %-24s %-10s %-8s %s\n",
+			truncate(index.Table, 20),
+			truncate(index.Name, 24),
+			FormatSize(index.SizeBytes),
+			scans,
+			truncate(index.Definition, 60)))
+	}
+
+	return sb.String()
+}
+
+// refreshSlowQueries перечитывает медленные запросы после смены сортировки или сброса
+func (m *DBModel) refreshSlowQueries() {
+	if m.stats == nil {
+		return
+	}
+	slowQueries, err := m.monitor.GetSlowQueries()
+	m.slowErr = err
+	if err == nil {
+		m.stats.SlowQueries = slowQueries
+	}
+}
+
+// selectedSlowQuery медленный запрос под курсором или nil
+func (m *DBModel) selectedSlowQuery() *SlowQuery {
+	if m.selectedTab != tabSlowQueries || m.cursor >= m.listLen() {
+		return nil
+	}
+	return &m.stats.SlowQueries[m.cursor]
+}
+
+// explainSelected получает план запроса под курсором. Нормализованный текст с параметрами
+// объяснить нельзя, поэтому при наличии берется пример с конкретными значениями
+func (m *DBModel) explainSelected(analyze bool) {
+	query := m.selectedSlowQuery()
+	if query == nil {
+		return
+	}
+
+	text := query.Query
+	if query.Sample != "" {
+		text = query.Sample
+	}
+
+	m.plan, m.planErr = m.monitor.Explain(text, analyze)
+	m.showPlan = true
+	m.planCursor = 0
+	m.planCollapsed = make(map[*PlanNode]bool)
+}
+
+// openSchemas загружает схемы и открывает выбор; ошибка показывается на вкладке таблиц
+func (m *DBModel) openSchemas() {
+	schemas, err := m.monitor.ListSchemas()
+	m.tableErr = err
+	if err != nil {
+		return
+	}
+
+	m.schemas = append([]string{"", AllSchemas}, schemas...)
+	m.schemaCursor = 0
+	for i, schema := range m.schemas {
+		if schema == m.monitor.Schema() {
+			m.schemaCursor = i
+		}
+	}
+	m.showSchemas = true
+}
+
+func (m *DBModel) updateSchemas(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.showSchemas = false
+	case "up":
+		if m.schemaCursor > 0 {
+			m.schemaCursor--
+		}
+	case "down":
+		if m.schemaCursor < len(m.schemas)-1 {
+			m.schemaCursor++
+		}
+	case "enter":
+		m.monitor.SetSchema(m.schemas[m.schemaCursor])
+		m.showSchemas = false
+		m.refreshTables()
+	}
+	return m, nil
+}
+
+// refreshTables перечитывает таблицы сразу после смены схемы, не дожидаясь тика
+func (m *DBModel) refreshTables() {
+	if m.stats == nil {
+		return
+	}
+	tables, err := m.monitor.GetTables()
+	m.tableErr = err
+	if err == nil {
+		m.stats.Tables = tables
+		m.stats.TableCount = len(tables)
+		m.trackTables()
+	}
+}
+
+// trackTables добавляет свежие таблицы в трекер роста и подставляет посчитанные COUNT(*).
+// Трекер видит оценки из статистики, чтобы точные значения не давали ложного скачка
+func (m *DBModel) trackTables() {
+	m.growth.Track(m.stats.Tables, time.Now())
+	for i := range m.stats.Tables {
+		if count, ok := m.exactRows[m.stats.Tables[i].QualifiedName()]; ok {
+			m.stats.Tables[i].Rows, m.stats.Tables[i].RowsExact = count, true
+		}
+	}
+}
+
+// displayedTables таблицы в порядке вывода: по умолчанию как вернула СУБД, с g - по скорости роста
+func (m *DBModel) displayedTables() []TableInfo {
+	tables := slices.Clone(m.stats.Tables)
+	if m.sortByGrowth {
+		slices.SortStableFunc(tables, func(a, b TableInfo) int {
+			return cmp.Compare(growthSortKey(b), growthSortKey(a))
+		})
+	}
+	return tables
+}
+
+// growthSortKey скорость роста для сортировки; таблицы без оценки идут последними
+func growthSortKey(table TableInfo) float64 {
+	if table.Growth == nil {
+		return math.Inf(-1)
+	}
+	return table.Growth.BytesPerHour
+}
+
+// selectedTable таблица под курсором или nil
+func (m *DBModel) selectedTable() *TableInfo {
+	if m.selectedTab != tabTables || m.healthView || m.cursor >= m.listLen() {
+		return nil
+	}
+	return &m.displayedTables()[m.cursor]
+}
+
+// countSelected точно считает строки таблицы; результат сохраняется до выхода из мониторинга
+func (m *DBModel) countSelected(table TableInfo) {
+	count, err := m.monitor.CountRows(table)
+	m.tableErr = err
+	if err != nil {
+		return
+	}
+	m.exactRows[table.QualifiedName()] = count
+	m.trackTables()
+}
+
+// hasTableHealth есть ли у таблиц состояние vacuum (только PostgreSQL)
+func (m *DBModel) hasTableHealth() bool {
+	if m.stats == nil {
+		return false
+	}
+	for _, table := range m.stats.Tables {
+		if table.Health != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *DBModel) renderSchemas() string {
+	var sb strings.Builder
+	sb.WriteString("Выбор схемы:\n\n")
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#FF6B6B"))
+	for i, schema := range m.schemas {
+		line := schemaLabel(schema)
+		if schema == m.monitor.Schema() {
+			line += " (текущая)"
+		}
+		if i == m.schemaCursor {
+			sb.WriteString(selectedStyle.Render("> "+line) + "\n")
+		} else {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// schemaLabel подпись схемы для заголовка вкладки и выбора
+func schemaLabel(schema string) string {
+	switch schema {
+	case "":
+		return "по умолчанию"
+	case AllSchemas:
+		return "все схемы"
+	}
+	return schema
+}
+
+// updatePlan обрабатывает клавиши в просмотре плана
+func (m *DBModel) updatePlan(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var rows []PlanRow
+	if m.plan != nil {
+		rows = m.plan.Rows(m.planCollapsed)
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.showPlan = false
+		m.plan, m.planErr = nil, nil
+	case "up":
+		if m.planCursor > 0 {
+			m.planCursor--
+		}
+	case "down":
+		if m.planCursor < len(rows)-1 {
+			m.planCursor++
+		}
+	case "enter", " ":
+		if m.planCursor < len(rows) && len(rows[m.planCursor].Node.Children) > 0 {
+			node := rows[m.planCursor].Node
+			m.planCollapsed[node] = !m.planCollapsed[node]
+		}
+	}
+	return m, nil
+}
+
+func (m *DBModel) renderPlan() string {
+	if m.planErr != nil {
+		return fmt.Sprintf("Не удалось получить план: %v", m.planErr)
+	}
+	if m.plan == nil {
+		return "Нет плана"
+	}
+
+	var sb strings.Builder
+
+	title := "План запроса"
+	switch {
+	case m.plan.Analyzed:
+		title += " (EXPLAIN ANALYZE, изменения откачены)"
+	case m.plan.Generic:
+		title += " (обобщенный план для параметров)"
+	}
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n\n")
+	sb.WriteString(m.plan.Query + "\n\n")
+
+	if m.plan.PlanningTime >= 0 {
+		sb.WriteString(fmt.Sprintf("Планирование: %v", m.plan.PlanningTime))
+		if m.plan.ExecutionTime >= 0 {
+			sb.WriteString(fmt.Sprintf(" | Выполнение: %v", m.plan.ExecutionTime))
+		}
+		sb.WriteString("\n\n")
+	} else if m.plan.ExecutionTime >= 0 {
+		sb.WriteString(fmt.Sprintf("Выполнение: %v\n\n", m.plan.ExecutionTime))
+	}
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#FF6B6B"))
+	expensiveStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true)
+	misestimateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAA00"))
+	detailStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+
+	expensive := m.plan.MostExpensive()
+	rows := m.plan.Rows(m.planCollapsed)
+	for i, row := range rows {
+		marker := "•"
+		if len(row.Node.Children) > 0 {
+			marker = "▾"
+			if m.planCollapsed[row.Node] {
+				marker = fmt.Sprintf("▸ (+%d)", len(row.Node.Children))
+			}
+		}
+		line := strings.Repeat("  ", row.Depth) + marker + " " + row.Node.Summary()
+		if row.Node == expensive {
+			line += "  ◀ самый дорогой узел"
+		}
+
+		switch {
+		case i == m.planCursor:
+			sb.WriteString(selectedStyle.Render("> "+line) + "\n")
+		case row.Node == expensive:
+			sb.WriteString(expensiveStyle.Render("  "+line) + "\n")
+		case row.Node.Misestimate() >= 10:
+			sb.WriteString(misestimateStyle.Render("  "+line) + "\n")
+		default:
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+
+	if m.planCursor < len(rows) {
+		node := rows[m.planCursor].Node
+		if len(node.Details) > 0 {
+			sb.WriteString("\n")
+			for _, detail := range node.Details {
+				sb.WriteString(detailStyle.Render("  "+detail) + "\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// truncate обрезает строку до заданной длины в символах
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	if limit <= 3 {
+		return string(runes[:limit])
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
 func (m *DBModel) renderProgressBar(percent float64, width int) string {
 	filled := int(percent * float64(width))
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// postgresXIDLimit примерное число транзакций до wraparound: дальше 2^31 PostgreSQL
+// перестает выдавать новые XID, пока таблица не будет заморожена VACUUM
+const postgresXIDLimit = 1<<31 - 1
+
+// Пороги, после которых таблица считается проблемной
+const (
+	deadTuplesRiskPct  = 20   // доля мертвых строк, %
+	deadTuplesRiskMin  = 1000 // мелкие таблицы с парой мертвых строк не интересны
+	bloatRiskPct       = 50   // оценка раздувания, %
+	wraparoundRiskPart = 10   // остаток до wraparound меньше 1/10 предела
+)
+
+// TableHealth состояние таблицы PostgreSQL: мертвые строки, раздувание, vacuum и возраст XID
+type TableHealth struct {
+	LiveTuples      int64
+	DeadTuples      int64
+	BloatPercent    float64   // оценка по статистике планировщика; -1, если таблица не анализировалась
+	LastVacuum      time.Time // нулевое время - не выполнялся
+	LastAutovacuum  time.Time
+	LastAnalyze     time.Time
+	LastAutoanalyze time.Time
+	XIDAge          int64 // age(relfrozenxid)
+	FreezeMaxAge    int64 // autovacuum_freeze_max_age, после него запускается принудительный autovacuum
+	Vacuum          *VacuumProgress
+}
+
+// VacuumProgress выполняющийся VACUUM из pg_stat_progress_vacuum
+type VacuumProgress struct {
+	PID              int
+	Phase            string
+	HeapBlksTotal    int64
+	HeapBlksScanned  int64
+	HeapBlksVacuumed int64
+	IndexVacuumCount int64
+}
+
+// Percent доля просканированных страниц кучи
+func (v *VacuumProgress) Percent() float64 {
+	if v.HeapBlksTotal == 0 {
+		return 0
+	}
+	return float64(v.HeapBlksScanned) / float64(v.HeapBlksTotal) * 100
+}
+
+// DeadPercent доля мертвых строк среди всех строк таблицы
+func (h *TableHealth) DeadPercent() float64 {
+	total := h.LiveTuples + h.DeadTuples
+	if total == 0 {
+		return 0
+	}
+	return float64(h.DeadTuples) / float64(total) * 100
+}
+
+// WraparoundRemaining сколько транзакций осталось до wraparound
+func (h *TableHealth) WraparoundRemaining() int64 {
+	return postgresXIDLimit - h.XIDAge
+}
+
+// LastVacuumed время последнего ручного или автоматического VACUUM
+func (h *TableHealth) LastVacuumed() time.Time {
+	if h.LastAutovacuum.After(h.LastVacuum) {
+		return h.LastAutovacuum
+	}
+	return h.LastVacuum
+}
+
+// LastAnalyzed время последнего ручного или автоматического ANALYZE
+func (h *TableHealth) LastAnalyzed() time.Time {
+	if h.LastAutoanalyze.After(h.LastAnalyze) {
+		return h.LastAutoanalyze
+	}
+	return h.LastAnalyze
+}
+
+// Risks причины, по которым таблице нужно внимание; пусто, если все в порядке
+func (h *TableHealth) Risks() []string {
+	var risks []string
+	if h.DeadTuples >= deadTuplesRiskMin && h.DeadPercent() >= deadTuplesRiskPct {
+		risks = append(risks, fmt.Sprintf("мертвых строк %.0f%%", h.DeadPercent()))
+	}
+	if h.BloatPercent >= bloatRiskPct {
+		risks = append(risks, fmt.Sprintf("раздувание ~%.0f%%", h.BloatPercent))
+	}
+	if h.WraparoundRemaining() < postgresXIDLimit/wraparoundRiskPart {
+		risks = append(risks, fmt.Sprintf("до wraparound %s транзакций", FormatCount(h.WraparoundRemaining())))
+	} else if h.FreezeMaxAge > 0 && h.XIDAge >= h.FreezeMaxAge {
+		risks = append(risks, "возраст XID выше autovacuum_freeze_max_age")
+	}
+	return risks
+}
+
+// FormatCount сокращает большие счетчики: 1.2K, 3.4M, 1.5B
+func FormatCount(value int64) string {
+	const unit = 1000
+	if value < unit && value > -unit {
+		return strconv.FormatInt(value, 10)
+	}
+	div, exp := int64(unit), 0
+	for n := value / unit; n >= unit || n <= -unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(value)/float64(div), "KMBT"[exp])
+}
+
+// FormatSince форматирует давность события; нулевое время - "никогда"
+func FormatSince(t time.Time) string {
+	if t.IsZero() {
+		return "никогда"
+	}
+	since := time.Since(t)
+	switch {
+	case since < time.Minute:
+		return "только что"
+	case since < time.Hour:
+		return fmt.Sprintf("%dм назад", int(since.Minutes()))
+	case since < 48*time.Hour:
+		return fmt.Sprintf("%dч назад", int(since.Hours()))
+	default:
+		return fmt.Sprintf("%dд назад", int(since.Hours()/24))
+	}
+}
+
+// postgresTableHealthQuery мертвые строки, оценка раздувания, vacuum/analyze, возраст XID
+// и текущий VACUUM по таблицам выбранной схемы. Раздувание оценивается сравнением relpages с
+// ожидаемым числом страниц по reltuples и средней ширине строки из pg_stats (28 байт -
+// заголовок строки и указатель на нее, 24 байта - заголовок страницы). Времена - unix-секунды, 0 - никогда
+func postgresTableHealthQuery(schema string) string {
+	return `
+		SELECT
+			s.schemaname,
+			s.relname,
+			s.n_live_tup,
+			s.n_dead_tup,
+			CASE WHEN c.relpages > 0 AND c.reltuples >= 0 AND w.width IS NOT NULL
+				THEN round(greatest(0, 100 * (1 - ceil(c.reltuples * (w.width + 28) /
+					(current_setting('block_size')::numeric - 24)) / c.relpages))::numeric, 1)
+				ELSE -1
+			END,
+			COALESCE(extract(epoch FROM s.last_vacuum)::bigint, 0),
+			COALESCE(extract(epoch FROM s.last_autovacuum)::bigint, 0),
+			COALESCE(extract(epoch FROM s.last_analyze)::bigint, 0),
+			COALESCE(extract(epoch FROM s.last_autoanalyze)::bigint, 0),
+			age(c.relfrozenxid),
+			current_setting('autovacuum_freeze_max_age'),
+			COALESCE(p.pid, 0),
+			COALESCE(p.phase, ''),
+			COALESCE(p.heap_blks_total, 0),
+			COALESCE(p.heap_blks_scanned, 0),
+			COALESCE(p.heap_blks_vacuumed, 0),
+			COALESCE(p.index_vacuum_count, 0)
+		FROM pg_stat_user_tables s
+		JOIN pg_class c ON c.oid = s.relid
+		LEFT JOIN (
+			SELECT schemaname, tablename, sum(avg_width) AS width
+			FROM pg_stats
+			GROUP BY schemaname, tablename
+		) w ON w.schemaname = s.schemaname AND w.tablename = s.relname
+		LEFT JOIN pg_stat_progress_vacuum p ON p.relid = s.relid
+		WHERE ` + postgresSchemaFilter("s.schemaname", schema)
+}
+
+// postgresTableHealth разбирает строки postgresTableHealthQuery в карту по имени таблицы со схемой
+func postgresTableHealth(rows [][]string) map[string]*TableHealth {
+	health := make(map[string]*TableHealth)
+	for _, row := range rows {
+		bloat, err := strconv.ParseFloat(strings.TrimSpace(row[4]), 64)
+		if err != nil {
+			bloat = -1
+		}
+
+		h := &TableHealth{
+			LiveTuples:      parseInt64(row[2]),
+			DeadTuples:      parseInt64(row[3]),
+			BloatPercent:    bloat,
+			LastVacuum:      parseUnixTime(row[5]),
+			LastAutovacuum:  parseUnixTime(row[6]),
+			LastAnalyze:     parseUnixTime(row[7]),
+			LastAutoanalyze: parseUnixTime(row[8]),
+			XIDAge:          parseInt64(row[9]),
+			FreezeMaxAge:    parseInt64(row[10]),
+		}
+		if pid := parseInt64(row[11]); pid != 0 {
+			h.Vacuum = &VacuumProgress{
+				PID:              int(pid),
+				Phase:            row[12],
+				HeapBlksTotal:    parseInt64(row[13]),
+				HeapBlksScanned:  parseInt64(row[14]),
+				HeapBlksVacuumed: parseInt64(row[15]),
+				IndexVacuumCount: parseInt64(row[16]),
+			}
+		}
+		health[row[0]+"."+row[1]] = h
+	}
+	return health
+}
+
+// attachTableHealth дополняет таблицы состоянием из postgresTableHealth
+func attachTableHealth(tables []TableInfo, health map[string]*TableHealth) {
+	for i := range tables {
+		tables[i].Health = health[tables[i].QualifiedName()]
+	}
+}
+
+// parseUnixTime переводит unix-секунды в время; 0 - нулевое время
+func parseUnixTime(value string) time.Time {
+	seconds := parseInt64(value)
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
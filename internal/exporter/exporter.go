@@ -0,0 +1,262 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"uno/internal/database"
+	"uno/internal/teas"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// Exporter отдает метрики хоста, баз данных и Docker в текстовом формате Prometheus.
+// Реализует http.Handler, поэтому может быть смонтирован в любой mux или проверен через httptest.
+type Exporter struct {
+	// Collector сборщик метрик хоста; nil отключает метрики хоста
+	Collector *teas.Collector
+	// Monitors подключенные мониторы БД по имени, используемому в метке db
+	Monitors map[string]database.DBMonitor
+	// Docker включает метрики состояния контейнеров
+	Docker bool
+
+	// mu сериализует сборы: сэмплеры считают скорости между вызовами
+	mu sync.Mutex
+}
+
+// ServeHTTP собирает все метрики и пишет их в ответ
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	pw := newPromWriter(w)
+	if e.Collector != nil {
+		writeHostMetrics(pw, e.Collector.Collect())
+	}
+	if len(e.Monitors) > 0 {
+		writeDBMetrics(pw, e.Monitors)
+	}
+	if e.Docker {
+		writeDockerMetrics(r.Context(), pw)
+	}
+}
+
+// sample одно значение метрики с метками
+type sample struct {
+	labels []string // пары имя, значение
+	value  float64
+}
+
+// promWriter группирует значения по семействам, чтобы HELP/TYPE печатались один раз
+type promWriter struct {
+	w io.Writer
+}
+
+func newPromWriter(w io.Writer) *promWriter {
+	return &promWriter{w: w}
+}
+
+// family печатает семейство метрик типа gauge
+func (p *promWriter) family(name, help string, samples ...sample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	fmt.Fprintf(p.w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(p.w, "# TYPE %s gauge\n", name)
+	for _, s := range samples {
+		fmt.Fprintf(p.w, "%s%s %s\n", name, formatLabels(s.labels),
+			strconv.FormatFloat(s.value, 'g', -1, 64))
+	}
+}
+
+// gauge печатает метрику без меток
+func (p *promWriter) gauge(name, help string, value float64) {
+	p.family(name, help, sample{value: value})
+}
+
+// labelEscaper экранирует значения меток по правилам текстового формата Prometheus
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func writeHostMetrics(p *promWriter, snap teas.Snapshot) {
+	p.gauge("uno_memory_total_bytes", "Total physical memory.", float64(snap.Memory.Total))
+	p.gauge("uno_memory_available_bytes", "Memory available for new allocations.", float64(snap.Memory.Available))
+	p.gauge("uno_memory_used_bytes", "Used physical memory.", float64(snap.Memory.Used))
+	p.gauge("uno_memory_used_percent", "Used physical memory in percent.", snap.Memory.UsedPct)
+
+	cpuSamples := []sample{{labels: []string{"cpu", "total"}, value: snap.CPU.Total.Total}}
+	for _, core := range snap.CPU.Cores {
+		cpuSamples = append(cpuSamples, sample{labels: []string{"cpu", core.Name}, value: core.Total})
+	}
+	p.family("uno_cpu_usage_percent", "CPU utilization since the previous scrape.", cpuSamples...)
+	p.family("uno_load_average", "System load average.",
+		sample{labels: []string{"period", "1m"}, value: snap.CPU.Load1},
+		sample{labels: []string{"period", "5m"}, value: snap.CPU.Load5},
+		sample{labels: []string{"period", "15m"}, value: snap.CPU.Load15})
+	p.gauge("uno_swap_used_bytes", "Used swap space.", float64(snap.CPU.SwapUsed))
+	p.gauge("uno_swap_total_bytes", "Total swap space.", float64(snap.CPU.SwapTotal))
+
+	var size, used, inodes []sample
+	for _, mnt := range snap.Mounts {
+		labels := []string{"mountpoint", mnt.Mountpoint, "device", mnt.Device, "fstype", mnt.Fstype}
+		size = append(size, sample{labels: labels, value: float64(mnt.Total)})
+		used = append(used, sample{labels: labels, value: float64(mnt.Used)})
+		inodes = append(inodes, sample{labels: labels, value: mnt.InodesPct})
+	}
+	p.family("uno_filesystem_size_bytes", "Filesystem size.", size...)
+	p.family("uno_filesystem_used_bytes", "Used filesystem space.", used...)
+	p.family("uno_filesystem_inodes_used_percent", "Used inodes in percent.", inodes...)
+
+	var readBytes, writeBytes, util []sample
+	for _, dev := range snap.DiskIO {
+		labels := []string{"device", dev.Name}
+		readBytes = append(readBytes, sample{labels: labels, value: dev.ReadBytesPerSec})
+		writeBytes = append(writeBytes, sample{labels: labels, value: dev.WriteBytesPerSec})
+		util = append(util, sample{labels: labels, value: dev.UtilPct})
+	}
+	p.family("uno_disk_read_bytes_per_second", "Disk read throughput since the previous scrape.", readBytes...)
+	p.family("uno_disk_write_bytes_per_second", "Disk write throughput since the previous scrape.", writeBytes...)
+	p.family("uno_disk_utilization_percent", "Time the device was busy in percent.", util...)
+
+	var rx, tx, up []sample
+	for _, iface := range snap.Network.Interfaces {
+		labels := []string{"interface", iface.Name}
+		rx = append(rx, sample{labels: labels, value: iface.RxBytesPerSec})
+		tx = append(tx, sample{labels: labels, value: iface.TxBytesPerSec})
+		up = append(up, sample{labels: labels, value: boolValue(iface.Up)})
+	}
+	p.family("uno_network_receive_bytes_per_second", "Received bytes per second since the previous scrape.", rx...)
+	p.family("uno_network_transmit_bytes_per_second", "Transmitted bytes per second since the previous scrape.", tx...)
+	p.family("uno_network_interface_up", "Whether the interface is up.", up...)
+	if snap.Network.OpenConnections >= 0 {
+		p.gauge("uno_network_open_connections", "Open network connections.", float64(snap.Network.OpenConnections))
+	}
+
+	p.gauge("uno_processes", "Number of processes.", float64(snap.ProcessCount))
+	p.gauge("uno_threads", "Number of threads.", float64(snap.ThreadCount))
+	p.gauge("uno_host_collect_errors", "Errors while collecting host metrics.", float64(len(snap.Errors)))
+}
+
+func writeDBMetrics(p *promWriter, monitors map[string]database.DBMonitor) {
+	names := make([]string, 0, len(monitors))
+	for name := range monitors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var up, active, maxConns, size, qps, latency, errs, slow, tableSize, tableRows []sample
+	for _, name := range names {
+		labels := []string{"db", name}
+
+		stats, err := monitors[name].GetStats()
+		if err != nil {
+			up = append(up, sample{labels: labels, value: 0})
+			continue
+		}
+		up = append(up, sample{labels: labels, value: 1})
+
+		active = append(active, sample{labels: labels, value: float64(stats.ActiveConnections)})
+		maxConns = append(maxConns, sample{labels: labels, value: float64(stats.MaxConnections)})
+		if bytes, ok := database.ParseSize(stats.DatabaseSize); ok {
+			size = append(size, sample{labels: labels, value: bytes})
+		}
+		qps = append(qps, sample{labels: labels, value: stats.QueriesPerSecond})
+		latency = append(latency, sample{labels: labels, value: stats.AvgResponseTime.Seconds()})
+		errs = append(errs, sample{labels: labels, value: float64(stats.ErrorCount)})
+		slow = append(slow, sample{labels: labels, value: float64(len(stats.SlowQueries))})
+
+		for _, table := range stats.Tables {
+			tableLabels := []string{"db", name, "table", table.QualifiedName()}
+			if bytes, ok := database.ParseSize(table.Size); ok {
+				tableSize = append(tableSize, sample{labels: tableLabels, value: bytes})
+			}
+			if table.Rows >= 0 {
+				tableRows = append(tableRows, sample{labels: tableLabels, value: float64(table.Rows)})
+			}
+		}
+	}
+
+	p.family("uno_db_up", "Whether the last database query succeeded.", up...)
+	p.family("uno_db_active_connections", "Active database connections.", active...)
+	p.family("uno_db_max_connections", "Maximum allowed database connections.", maxConns...)
+	p.family("uno_db_size_bytes", "Database size.", size...)
+	p.family("uno_db_queries_per_second", "Queries or transactions per second since the previous scrape.", qps...)
+	p.family("uno_db_avg_response_seconds", "Average statement latency since the previous scrape.", latency...)
+	p.family("uno_db_errors", "Rollbacks and errors since the exporter started.", errs...)
+	p.family("uno_db_slow_queries", "Number of reported slow queries.", slow...)
+	p.family("uno_db_table_size_bytes", "Table size including indexes.", tableSize...)
+	p.family("uno_db_table_rows", "Table rows, estimated from statistics where exact counts are expensive.", tableRows...)
+}
+
+func writeDockerMetrics(ctx context.Context, p *promWriter) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		p.gauge("uno_docker_up", "Whether the Docker daemon is reachable.", 0)
+		return
+	}
+	defer cli.Close()
+
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		p.gauge("uno_docker_up", "Whether the Docker daemon is reachable.", 0)
+		return
+	}
+	p.gauge("uno_docker_up", "Whether the Docker daemon is reachable.", 1)
+
+	counts := make(map[string]int)
+	var states []sample
+	for _, c := range containers {
+		name := c.ID
+		if len(name) > 12 {
+			name = name[:12]
+		}
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+
+		state := string(c.State)
+		counts[state]++
+		states = append(states, sample{
+			labels: []string{"name", name, "image", c.Image, "state", state},
+			value:  boolValue(state == "running"),
+		})
+	}
+
+	var totals []sample
+	for _, state := range []string{"created", "running", "paused", "restarting", "removing", "exited", "dead"} {
+		totals = append(totals, sample{labels: []string{"state", state}, value: float64(counts[state])})
+	}
+	p.family("uno_docker_containers", "Number of containers by state.", totals...)
+	p.family("uno_docker_container_running", "Whether the container is running; state label holds the exact state.", states...)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
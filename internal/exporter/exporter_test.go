@@ -0,0 +1,130 @@
+package exporter
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"uno/internal/database"
+)
+
+// fakeMonitor отдает заранее заданную статистику; остальные методы DBMonitor экспортеру не нужны
+type fakeMonitor struct {
+	database.DBMonitor
+	stats *database.DBStats
+	err   error
+}
+
+func (f *fakeMonitor) GetStats() (*database.DBStats, error) {
+	return f.stats, f.err
+}
+
+func scrape(t *testing.T, e *Exporter) string {
+	t.Helper()
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestServeHTTP(t *testing.T) {
+	e := &Exporter{Monitors: map[string]database.DBMonitor{
+		"main": &fakeMonitor{stats: &database.DBStats{
+			ActiveConnections: 3,
+			MaxConnections:    100,
+			DatabaseSize:      "2.00 MB",
+			Tables: []database.TableInfo{
+				{Schema: "public", Name: "orders", Size: "1.00 kB", Rows: 10},
+				{Schema: "public", Name: `we"ird\name`, Size: "2.00 kB", Rows: -1},
+			},
+		}},
+		"replica": &fakeMonitor{err: errors.New("connection refused")},
+	}}
+
+	body := scrape(t, e)
+
+	for _, want := range []string{
+		`uno_db_up{db="main"} 1`,
+		`uno_db_up{db="replica"} 0`,
+		`uno_db_active_connections{db="main"} 3`,
+		`uno_db_size_bytes{db="main"} 2.097152e+06`,
+		`uno_db_table_size_bytes{db="main",table="public.orders"} 1024`,
+		`uno_db_table_size_bytes{db="main",table="public.we\"ird\\name"} 2048`,
+		`uno_db_table_rows{db="main",table="public.orders"} 10`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+
+	// Недоступная БД не дает остальных метрик, а таблица без оценки строк - uno_db_table_rows
+	if strings.Contains(body, `uno_db_active_connections{db="replica"}`) {
+		t.Errorf("metrics reported for a database that failed to respond")
+	}
+	if strings.Contains(body, `uno_db_table_rows{db="main",table="public.we`) {
+		t.Errorf("rows reported for a table with unknown row count")
+	}
+
+	help := make(map[string]int)
+	types := make(map[string]int)
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+			help[fields[2]]++
+		case strings.HasPrefix(line, "# TYPE "):
+			types[fields[2]]++
+		}
+	}
+	if len(help) == 0 {
+		t.Fatalf("no HELP lines in:\n%s", body)
+	}
+	for name, count := range help {
+		if count != 1 {
+			t.Errorf("HELP for %s appears %d times", name, count)
+		}
+		if types[name] != 1 {
+			t.Errorf("TYPE for %s appears %d times", name, types[name])
+		}
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	tests := []struct {
+		labels []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"db", "main"}, `{db="main"}`},
+		{[]string{"db", "main", "table", "orders"}, `{db="main",table="orders"}`},
+		{[]string{"path", `C:\data`}, `{path="C:\\data"}`},
+		{[]string{"name", `say "hi"`}, `{name="say \"hi\""}`},
+		{[]string{"msg", "line1\nline2"}, `{msg="line1\nline2"}`},
+	}
+
+	for _, tt := range tests {
+		if got := formatLabels(tt.labels); got != tt.want {
+			t.Errorf("formatLabels(%q) = %s, want %s", tt.labels, got, tt.want)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	"uno/internal/alerts"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -21,12 +22,13 @@ import (
 )
 
 type LogEntry struct {
-	Time    string `json:"time"`
-	Level   string `json:"level"`
-	Message string `json:"msg"`
-	JobID   string `json:"jobID,omitempty"`
-	Error   string `json:"error,omitempty"`
-	Raw     string // оригинальная строка для непарсящихся логов
+	Time    string    `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"msg"`
+	JobID   string    `json:"jobID,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Raw     string    // оригинальная строка для непарсящихся логов
+	At      time.Time `json:"-"` // метка Docker (Timestamps: true); нулевая, если ее нет
 }
 
 type model struct {
@@ -41,8 +43,14 @@ type model struct {
 	logCount       int
 	requestedTail  int
 	scrollOffset   int // Смещение для прокрутки
+	alerts         *alerts.Engine
+	errorTimes     []time.Time // метки ошибок за окно errorRateWindow
+	errorCount     int
 }
 
+// errorRateWindow окно, за которое считается logs.error_rate
+const errorRateWindow = time.Minute
+
 func initialModel(containerID string) model {
 	// Получаем размер терминала
 	width, height, err := term.GetSize(int(os.Stdout.Fd()))
@@ -64,7 +72,7 @@ func initialModel(containerID string) model {
 	}
 }
 
-func RunLogs(containerID string, errorsOnly bool, tail int) error {
+func RunLogs(containerID string, errorsOnly bool, tail int, engine *alerts.Engine) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -78,6 +86,7 @@ func RunLogs(containerID string, errorsOnly bool, tail int) error {
 	model := initialModel(containerID)
 	model.showErrorsOnly = errorsOnly
 	model.requestedTail = tail
+	model.alerts = engine
 	p := tea.NewProgram(model)
 	go streamLogs(ctx, p, containerID, tail)
 
@@ -98,7 +107,25 @@ func parseLogLine(line string) LogEntry {
 
 	// Убираем лишние пробелы
 	line = strings.TrimSpace(line)
+	at := dockerTimestamp(line)
+
+	entry := parseLogPayload(line)
+	entry.At = at
+	return entry
+}
 
+// dockerTimestamp метка времени, которую Docker добавляет в начало строки
+func dockerTimestamp(line string) time.Time {
+	field, _, _ := strings.Cut(line, " ")
+	at, err := time.Parse(time.RFC3339Nano, field)
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
+
+// parseLogPayload разбирает строку без заголовка потока Docker: JSON или текстовый формат
+func parseLogPayload(line string) LogEntry {
 	// Сначала пытаемся найти JSON часть лога
 	jsonStart := strings.Index(line, "{")
 	if jsonStart != -1 {
@@ -407,8 +434,40 @@ func streamLogs(ctx context.Context, p *tea.Program, containerID string, tail in
 type logMsg LogEntry
 type errorMsg struct{ err error }
 type infoMsg struct{ message string }
+type alertTickMsg time.Time
 
-func (m model) Init() tea.Cmd { return nil }
+func (m model) Init() tea.Cmd {
+	if m.alerts == nil {
+		return nil
+	}
+	return alertTick()
+}
+
+func alertTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return alertTickMsg(t)
+	})
+}
+
+// evaluateAlerts считает частоту ошибок по меткам строк и проверяет правила
+func (m model) evaluateAlerts(now time.Time) model {
+	cutoff := now.Add(-errorRateWindow)
+	// stdout и stderr приходят вперемешку, поэтому метки упорядочены не строго
+	recent := m.errorTimes[:0]
+	for _, at := range m.errorTimes {
+		if !at.Before(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	m.errorTimes = recent
+
+	m.alerts.Evaluate(map[string]float64{
+		"logs.error_rate": float64(len(m.errorTimes)) / errorRateWindow.Seconds(),
+		"logs.total":      float64(m.logCount),
+		"logs.errors":     float64(m.errorCount),
+	})
+	return m
+}
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch v := msg.(type) {
@@ -449,6 +508,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.logEntries = append(m.logEntries, LogEntry(v))
 		m.logCount++
+		if m.alerts != nil && isErrorLog(LogEntry(v)) {
+			// Строки из --tail пришли пачкой при старте: в частоту попадают только ошибки
+			// за последнее окно по их собственной метке, а не по времени получения
+			at := v.At
+			if at.IsZero() {
+				at = time.Now()
+			}
+			if time.Since(at) < errorRateWindow {
+				m.errorTimes = append(m.errorTimes, at)
+			}
+			m.errorCount++
+		}
 		// Убираем ограничение на количество логов в памяти
 		// Теперь храним все логи
 
@@ -461,6 +532,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ready = true
 	case infoMsg:
 		// Просто игнорируем info сообщения для отладки
+	case alertTickMsg:
+		m = m.evaluateAlerts(time.Time(v))
+		return m, alertTick()
 	}
 	return m, nil
 }
@@ -638,7 +712,8 @@ func (m model) View() string {
 
 	// Применяем прокрутку
 	start := m.scrollOffset
-	availableHeight := m.height - 3 // Оставляем место для header и footer
+	banner := m.alerts.Banner()
+	availableHeight := m.height - 3 - strings.Count(banner, "\n") // Оставляем место для header, footer и алертов
 	end := start + availableHeight
 	if end > len(visibleEntries) {
 		end = len(visibleEntries)
@@ -731,7 +806,7 @@ func (m model) View() string {
 		Foreground(lipgloss.Color("#888888")).
 		Render(fmt.Sprintf("\nPress 'q' or Ctrl+C to quit%s%s%s", wrapStatus, errorFilterStatus, scrollStatus))
 
-	return header + "\n" + logs + footer
+	return banner + header + "\n" + logs + footer
 }
 
 func normalizeLevel(level string) string {
@@ -9,6 +9,12 @@ import (
 )
 
 func RenderTable(data [][]string) string {
+	return RenderTableWithHeader([]string{"metrics", "value"}, data)
+}
+
+// RenderTableWithHeader рисует таблицу с произвольным заголовком.
+// Короткие строки дополняются пустыми ячейками до ширины заголовка.
+func RenderTableWithHeader(header []string, data [][]string) string {
 	colorCfg := renderer.ColorizedConfig{
 		Header: renderer.Tint{
 			FG: renderer.Colors{color.FgGreen, color.Bold},
@@ -50,8 +56,17 @@ func RenderTable(data [][]string) string {
 		}),
 	)
 
-	tbl.Header([]string{"metrics", "value"})
-	tbl.Bulk(data)
+	rows := make([][]string, 0, len(data))
+	for _, row := range data {
+		padded := append([]string(nil), row...)
+		for len(padded) < len(header) {
+			padded = append(padded, "")
+		}
+		rows = append(rows, padded)
+	}
+
+	tbl.Header(header)
+	tbl.Bulk(rows)
 	tbl.Render()
 
 	return buf.String()
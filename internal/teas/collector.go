@@ -0,0 +1,150 @@
+package teas
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// MemoryStats использование оперативной памяти
+type MemoryStats struct {
+	Total     uint64  `json:"total"`
+	Available uint64  `json:"available"`
+	Used      uint64  `json:"used"`
+	UsedPct   float64 `json:"usedPct"`
+}
+
+// DiskUsage заполненность корневой файловой системы
+type DiskUsage struct {
+	Used    uint64  `json:"used"`
+	Total   uint64  `json:"total"`
+	UsedPct float64 `json:"usedPct"`
+}
+
+// NetworkStats адреса, соединения и нагрузка на сетевые интерфейсы
+type NetworkStats struct {
+	IPs             []string         `json:"ips"`
+	OpenConnections int              `json:"openConnections"`
+	Interfaces      []InterfaceStats `json:"interfaces"`
+	Total           InterfaceStats   `json:"total"`
+}
+
+// TopProcess процесс с наибольшей загрузкой CPU
+type TopProcess struct {
+	Name   string   `json:"name"`
+	Status []string `json:"status"`
+	User   string   `json:"user"`
+	CPU    float64  `json:"cpu"`
+}
+
+// Snapshot все метрики системы на момент сбора
+type Snapshot struct {
+	Timestamp    time.Time     `json:"timestamp"`
+	Memory       MemoryStats   `json:"memory"`
+	CPU          CPUStats      `json:"cpu"`
+	Disk         DiskUsage     `json:"disk"`
+	Mounts       []MountStats  `json:"mounts"`
+	DiskIO       []DiskIOStats `json:"diskIO"`
+	Network      NetworkStats  `json:"network"`
+	TopProcess   TopProcess    `json:"topProcess"`
+	ProcessCount int           `json:"processCount"`
+	ThreadCount  int           `json:"threadCount"`
+	Processes    []ProcessInfo `json:"processes"`
+	Errors       []string      `json:"errors,omitempty"`
+}
+
+// Collector собирает метрики системы независимо от TUI.
+// Скорости считаются между последовательными вызовами Collect.
+type Collector struct {
+	net   *NetSampler
+	procs *ProcessSampler
+	cpu   *CPUSampler
+	disk  *DiskSampler
+}
+
+// NewCollector создает сборщик метрик
+func NewCollector() *Collector {
+	return &Collector{
+		net:   NewNetSampler(),
+		procs: NewProcessSampler(),
+		cpu:   NewCPUSampler(),
+		disk:  NewDiskSampler(),
+	}
+}
+
+// Collect снимает все метрики; ошибки отдельных источников попадают в Snapshot.Errors
+func (c *Collector) Collect() Snapshot {
+	snap := Snapshot{Timestamp: time.Now()}
+	addErr := func(source string, err error) {
+		snap.Errors = append(snap.Errors, fmt.Sprintf("%s error: %v", source, err))
+	}
+
+	if v, err := mem.VirtualMemory(); err != nil {
+		addErr("Memory", err)
+	} else {
+		snap.Memory = MemoryStats{
+			Total:     v.Total,
+			Available: v.Available,
+			Used:      v.Used,
+			UsedPct:   v.UsedPercent,
+		}
+	}
+
+	var err error
+	if snap.Disk, err = CalcDisk(); err != nil {
+		addErr("Disk", err)
+	}
+	if snap.Mounts, err = CalcMounts(); err != nil {
+		addErr("Mounts", err)
+	}
+	if snap.DiskIO, err = c.disk.Sample(); err != nil {
+		addErr("Disk I/O", err)
+	}
+	if snap.CPU, err = c.cpu.Sample(); err != nil {
+		addErr("CPU", err)
+	}
+	if snap.Network, err = CalcNet(c.net); err != nil {
+		addErr("Network", err)
+	}
+
+	// Один проход по процессам на сбор
+	if snap.Processes, err = c.procs.Sample(); err != nil {
+		addErr("Process", err)
+	}
+	name, status, user, cpu := Process(snap.Processes)
+	snap.TopProcess = TopProcess{Name: name, Status: status, User: user, CPU: cpu}
+	snap.ProcessCount, snap.ThreadCount = ProcessSummary(snap.Processes)
+
+	return snap
+}
+
+// Metrics плоский набор метрик снимка для правил алертинга
+func (s Snapshot) Metrics() map[string]float64 {
+	metrics := map[string]float64{
+		"mem.used_pct":         s.Memory.UsedPct,
+		"mem.available_bytes":  float64(s.Memory.Available),
+		"mem.total_bytes":      float64(s.Memory.Total),
+		"cpu.total_pct":        s.CPU.Total.Total,
+		"cpu.load1":            s.CPU.Load1,
+		"cpu.load5":            s.CPU.Load5,
+		"cpu.load15":           s.CPU.Load15,
+		"swap.used_pct":        s.CPU.SwapUsedPct,
+		"disk.used_pct":        s.Disk.UsedPct,
+		"net.rx_bytes_per_sec": s.Network.Total.RxBytesPerSec,
+		"net.tx_bytes_per_sec": s.Network.Total.TxBytesPerSec,
+		"procs.count":          float64(s.ProcessCount),
+		"procs.threads":        float64(s.ThreadCount),
+	}
+
+	// Самая заполненная файловая система
+	if len(s.Mounts) > 0 {
+		maxPct := 0.0
+		for _, m := range s.Mounts {
+			maxPct = max(maxPct, m.UsedPct)
+		}
+		metrics["disk.max_used_pct"] = maxPct
+	}
+
+	return metrics
+}
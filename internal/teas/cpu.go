@@ -0,0 +1,180 @@
+package teas
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// CPUUsage загрузка CPU (или одного ядра) в процентах за последний интервал
+type CPUUsage struct {
+	Name   string  `json:"name"`
+	Total  float64 `json:"total"`
+	User   float64 `json:"user"`
+	System float64 `json:"system"`
+	Iowait float64 `json:"iowait"`
+	Steal  float64 `json:"steal"`
+}
+
+// CPUStats загрузка процессора, средняя нагрузка и подкачка
+type CPUStats struct {
+	Total             CPUUsage   `json:"total"`
+	Cores             []CPUUsage `json:"cores"`
+	Load1             float64    `json:"load1"`
+	Load5             float64    `json:"load5"`
+	Load15            float64    `json:"load15"`
+	CtxSwitchesPerSec float64    `json:"ctxSwitchesPerSec"`
+	InterruptsPerSec  float64    `json:"interruptsPerSec"`
+	SwapTotal         uint64     `json:"swapTotal"`
+	SwapUsed          uint64     `json:"swapUsed"`
+	SwapUsedPct       float64    `json:"swapUsedPct"`
+	SwapInPerSec      float64    `json:"swapInPerSec"`  // байт/с
+	SwapOutPerSec     float64    `json:"swapOutPerSec"` // байт/с
+}
+
+// CPUSampler считает загрузку CPU и скорости счетчиков ядра по разнице между вызовами
+type CPUSampler struct {
+	prevTotal cpu.TimesStat
+	prevCores map[string]cpu.TimesStat
+	prevCtxt  uint64
+	prevIntr  uint64
+	prevSin   uint64
+	prevSout  uint64
+	prevTime  time.Time
+}
+
+// NewCPUSampler создает сэмплер; в первом снимке скорости равны нулю
+func NewCPUSampler() *CPUSampler {
+	return &CPUSampler{}
+}
+
+// Sample возвращает текущий снимок загрузки CPU, load average и подкачки
+func (s *CPUSampler) Sample() (CPUStats, error) {
+	var stats CPUStats
+
+	now := time.Now()
+	elapsed := now.Sub(s.prevTime).Seconds()
+	first := s.prevTime.IsZero()
+
+	totals, err := cpu.Times(false)
+	if err != nil {
+		return stats, err
+	}
+	if len(totals) > 0 {
+		if !first {
+			stats.Total = cpuUsage(s.prevTotal, totals[0])
+		}
+		stats.Total.Name = "cpu"
+		s.prevTotal = totals[0]
+	}
+
+	cores, err := cpu.Times(true)
+	if err != nil {
+		return stats, err
+	}
+	prevCores := make(map[string]cpu.TimesStat, len(cores))
+	for _, core := range cores {
+		usage := CPUUsage{Name: core.CPU}
+		if prev, ok := s.prevCores[core.CPU]; ok {
+			usage = cpuUsage(prev, core)
+		}
+		stats.Cores = append(stats.Cores, usage)
+		prevCores[core.CPU] = core
+	}
+	s.prevCores = prevCores
+
+	if avg, err := load.Avg(); err == nil {
+		stats.Load1 = avg.Load1
+		stats.Load5 = avg.Load5
+		stats.Load15 = avg.Load15
+	}
+
+	if misc, err := load.Misc(); err == nil {
+		ctxt := uint64(misc.Ctxt)
+		if !first && elapsed > 0 {
+			stats.CtxSwitchesPerSec = rate(s.prevCtxt, ctxt, elapsed)
+		}
+		s.prevCtxt = ctxt
+	}
+
+	if intr, err := readInterrupts(); err == nil {
+		if !first && elapsed > 0 {
+			stats.InterruptsPerSec = rate(s.prevIntr, intr, elapsed)
+		}
+		s.prevIntr = intr
+	}
+
+	if swap, err := mem.SwapMemory(); err == nil {
+		stats.SwapTotal = swap.Total
+		stats.SwapUsed = swap.Used
+		stats.SwapUsedPct = swap.UsedPercent
+		if !first && elapsed > 0 {
+			stats.SwapInPerSec = rate(s.prevSin, swap.Sin, elapsed)
+			stats.SwapOutPerSec = rate(s.prevSout, swap.Sout, elapsed)
+		}
+		s.prevSin = swap.Sin
+		s.prevSout = swap.Sout
+	}
+
+	s.prevTime = now
+	return stats, nil
+}
+
+// cpuUsage переводит разницу счетчиков времени CPU в проценты.
+// Total, как и в cpu.Percent, не учитывает простой и ожидание ввода-вывода.
+func cpuUsage(prev, cur cpu.TimesStat) CPUUsage {
+	total := cpuTotalTime(cur) - cpuTotalTime(prev)
+	if total <= 0 {
+		return CPUUsage{Name: cur.CPU}
+	}
+
+	pct := func(a, b float64) float64 {
+		if b < a {
+			return 0
+		}
+		return (b - a) / total * 100
+	}
+
+	idle := pct(prev.Idle, cur.Idle)
+	iowait := pct(prev.Iowait, cur.Iowait)
+	busy := 100 - idle - iowait
+	if busy < 0 {
+		busy = 0
+	}
+
+	return CPUUsage{
+		Name:   cur.CPU,
+		Total:  busy,
+		User:   pct(prev.User+prev.Nice, cur.User+cur.Nice),
+		System: pct(prev.System+prev.Irq+prev.Softirq, cur.System+cur.Irq+cur.Softirq),
+		Iowait: iowait,
+		Steal:  pct(prev.Steal, cur.Steal),
+	}
+}
+
+// cpuTotalTime суммарное время CPU; guest уже учтен в user на Linux
+func cpuTotalTime(t cpu.TimesStat) float64 {
+	return t.User + t.System + t.Idle + t.Nice + t.Iowait + t.Irq + t.Softirq + t.Steal
+}
+
+// readInterrupts читает общее число прерываний из /proc/stat (только Linux)
+func readInterrupts() (uint64, error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "intr" {
+			return strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+
+	return 0, os.ErrNotExist
+}
@@ -0,0 +1,151 @@
+package teas
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// MountStats заполненность одной файловой системы
+type MountStats struct {
+	Mountpoint  string  `json:"mountpoint"`
+	Device      string  `json:"device"`
+	Fstype      string  `json:"fstype"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPct     float64 `json:"usedPct"`
+	InodesTotal uint64  `json:"inodesTotal"`
+	InodesUsed  uint64  `json:"inodesUsed"`
+	InodesPct   float64 `json:"inodesPct"`
+}
+
+// DiskIOStats нагрузка на блочное устройство за последний интервал
+type DiskIOStats struct {
+	Name             string  `json:"name"`
+	ReadBytesPerSec  float64 `json:"readBytesPerSec"`
+	WriteBytesPerSec float64 `json:"writeBytesPerSec"`
+	ReadIOPS         float64 `json:"readIOPS"`
+	WriteIOPS        float64 `json:"writeIOPS"`
+	UtilPct          float64 `json:"utilPct"`
+}
+
+// pseudoFilesystems виртуальные файловые системы, которые не хранят данные на дисках
+var pseudoFilesystems = map[string]bool{
+	"tmpfs": true, "devtmpfs": true, "devfs": true, "overlay": true, "squashfs": true,
+	"proc": true, "sysfs": true, "cgroup": true, "cgroup2": true, "autofs": true,
+	"debugfs": true, "tracefs": true, "securityfs": true, "pstore": true, "bpf": true,
+	"mqueue": true, "hugetlbfs": true, "configfs": true, "fusectl": true, "nsfs": true,
+	"ramfs": true, "binfmt_misc": true, "devpts": true, "efivarfs": true, "rpc_pipefs": true,
+	"nfsd": true, "fuse.gvfsd-fuse": true, "fuse.portal": true, "fuse.lxcfs": true,
+}
+
+// CalcMounts возвращает все реальные файловые системы, отсортированные по точке монтирования
+func CalcMounts() ([]MountStats, error) {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var mounts []MountStats
+	for _, part := range partitions {
+		if pseudoFilesystems[part.Fstype] || strings.HasPrefix(part.Mountpoint, "/snap/") || seen[part.Mountpoint] {
+			continue
+		}
+		seen[part.Mountpoint] = true
+
+		usage, err := disk.Usage(part.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+
+		mounts = append(mounts, MountStats{
+			Mountpoint:  part.Mountpoint,
+			Device:      part.Device,
+			Fstype:      part.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPct:     usage.UsedPercent,
+			InodesTotal: usage.InodesTotal,
+			InodesUsed:  usage.InodesUsed,
+			InodesPct:   usage.InodesUsedPercent,
+		})
+	}
+
+	sort.Slice(mounts, func(i, j int) bool {
+		return mounts[i].Mountpoint < mounts[j].Mountpoint
+	})
+
+	return mounts, nil
+}
+
+// DiskSampler считает скорости ввода-вывода устройств по разнице счетчиков между вызовами
+type DiskSampler struct {
+	prev     map[string]disk.IOCountersStat
+	prevTime time.Time
+}
+
+// sysClassBlock каталог блочных устройств в sysfs (только Linux)
+var sysClassBlock = "/sys/class/block"
+
+// isPartition является ли устройство разделом (sda1, nvme0n1p1): у разделов в sysfs есть файл partition.
+// Без sysfs (не Linux) устройство считается диском
+func isPartition(name string) bool {
+	_, err := os.Stat(filepath.Join(sysClassBlock, name, "partition"))
+	return err == nil
+}
+
+// NewDiskSampler создает сэмплер; первый вызов Sample возвращает нулевые скорости
+func NewDiskSampler() *DiskSampler {
+	return &DiskSampler{}
+}
+
+// Sample возвращает нагрузку на каждое блочное устройство
+func (s *DiskSampler) Sample() ([]DiskIOStats, error) {
+	counters, err := disk.IOCounters()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(s.prevTime).Seconds()
+
+	var result []DiskIOStats
+	for name, cur := range counters {
+		// Разделы повторяют нагрузку своего диска, а виртуальные устройства без активности
+		// только засоряют список
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || isPartition(name) {
+			continue
+		}
+
+		stats := DiskIOStats{Name: name}
+		if prev, ok := s.prev[name]; ok && elapsed > 0 {
+			stats.ReadBytesPerSec = rate(prev.ReadBytes, cur.ReadBytes, elapsed)
+			stats.WriteBytesPerSec = rate(prev.WriteBytes, cur.WriteBytes, elapsed)
+			stats.ReadIOPS = rate(prev.ReadCount, cur.ReadCount, elapsed)
+			stats.WriteIOPS = rate(prev.WriteCount, cur.WriteCount, elapsed)
+			// IoTime - миллисекунды, в течение которых устройство было занято
+			stats.UtilPct = rate(prev.IoTime, cur.IoTime, elapsed) / 10
+			if stats.UtilPct > 100 {
+				stats.UtilPct = 100
+			}
+		}
+
+		result = append(result, stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	s.prev = counters
+	s.prevTime = now
+
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package teas
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsPartition(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"sda", "sda1", "nvme0n1", "nvme0n1p1"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"sda1", "nvme0n1p1"} {
+		if err := os.WriteFile(filepath.Join(dir, name, "partition"), []byte("1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := sysClassBlock
+	sysClassBlock = dir
+	defer func() { sysClassBlock = saved }()
+
+	for name, want := range map[string]bool{
+		"sda":       false,
+		"sda1":      true,
+		"nvme0n1":   false,
+		"nvme0n1p1": true,
+		"dm-0":      false, // нет в sysfs
+	} {
+		if got := isPartition(name); got != want {
+			t.Errorf("isPartition(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
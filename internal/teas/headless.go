@@ -0,0 +1,50 @@
+package teas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// warmupDelay пауза между первыми двумя сборами, чтобы скорости в одиночном снимке были ненулевыми
+const warmupDelay = time.Second
+
+// RunHeadless печатает снимки метрик без TUI.
+// format: "json" - форматированный JSON, "ndjson" - один JSON-объект на строку.
+// При once печатается один снимок, иначе снимки выводятся каждые interval до ошибки записи.
+func RunHeadless(w io.Writer, format string, interval time.Duration, once bool) error {
+	encoder := json.NewEncoder(w)
+	switch format {
+	case "json":
+		encoder.SetIndent("", "  ")
+	case "ndjson":
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+
+	if interval <= 0 {
+		interval = tickInterval
+	}
+
+	collector := NewCollector()
+	collector.Collect()
+
+	delay := interval
+	if once {
+		delay = warmupDelay
+	}
+	ticker := time.NewTicker(delay)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := encoder.Encode(collector.Collect()); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		if once {
+			return nil
+		}
+	}
+
+	return nil
+}
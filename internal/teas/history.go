@@ -0,0 +1,67 @@
+package teas
+
+// history хранит скользящее окно значений метрики
+type history struct {
+	values []float64
+	size   int
+}
+
+func newHistory(size int) history {
+	if size < 1 {
+		size = 1
+	}
+	return history{size: size}
+}
+
+// add добавляет значение, вытесняя самые старые за пределами окна
+func (h history) add(v float64) history {
+	h.values = append(h.values, v)
+	if len(h.values) > h.size {
+		h.values = append([]float64(nil), h.values[len(h.values)-h.size:]...)
+	}
+	return h
+}
+
+// stats возвращает минимум, среднее и максимум за окно
+func (h history) stats() (minV, avgV, maxV float64) {
+	if len(h.values) == 0 {
+		return 0, 0, 0
+	}
+
+	minV, maxV = h.values[0], h.values[0]
+	var sum float64
+	for _, v := range h.values {
+		if v < minV {
+			minV = v
+		}
+		if v > maxV {
+			maxV = v
+		}
+		sum += v
+	}
+
+	return minV, sum / float64(len(h.values)), maxV
+}
+
+// buckets сжимает окно до width точек, беря максимум в каждой корзине,
+// чтобы кратковременные пики оставались видны на графике
+func (h history) buckets(width int) []float64 {
+	if width <= 0 || len(h.values) <= width {
+		return h.values
+	}
+
+	result := make([]float64, width)
+	for i := range result {
+		start := i * len(h.values) / width
+		end := (i + 1) * len(h.values) / width
+		maxV := h.values[start]
+		for _, v := range h.values[start:end] {
+			if v > maxV {
+				maxV = v
+			}
+		}
+		result[i] = maxV
+	}
+
+	return result
+}
@@ -0,0 +1,109 @@
+package teas
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// InterfaceStats текущая нагрузка на сетевой интерфейс
+type InterfaceStats struct {
+	Name            string   `json:"name"`
+	Addrs           []string `json:"addrs"`
+	MAC             string   `json:"mac"`
+	Up              bool     `json:"up"`
+	RxBytesPerSec   float64  `json:"rxBytesPerSec"`
+	TxBytesPerSec   float64  `json:"txBytesPerSec"`
+	RxPacketsPerSec float64  `json:"rxPacketsPerSec"`
+	TxPacketsPerSec float64  `json:"txPacketsPerSec"`
+	ErrorsPerSec    float64  `json:"errorsPerSec"`
+	DropsPerSec     float64  `json:"dropsPerSec"`
+}
+
+// NetSampler считает скорости интерфейсов по разнице счетчиков между вызовами
+type NetSampler struct {
+	prev     map[string]net.IOCountersStat
+	prevTime time.Time
+}
+
+// NewNetSampler создает пустой сэмплер; первый вызов Sample возвращает нулевые скорости
+func NewNetSampler() *NetSampler {
+	return &NetSampler{}
+}
+
+// Sample возвращает статистику по каждому интерфейсу и итоговую строку по всем интерфейсам
+func (s *NetSampler) Sample() ([]InterfaceStats, InterfaceStats, error) {
+	total := InterfaceStats{Name: "TOTAL", Up: true}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, total, err
+	}
+
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return nil, total, err
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(s.prevTime).Seconds()
+
+	current := make(map[string]net.IOCountersStat, len(counters))
+	for _, c := range counters {
+		current[c.Name] = c
+	}
+
+	var result []InterfaceStats
+	for _, iface := range ifaces {
+		stats := InterfaceStats{
+			Name: iface.Name,
+			MAC:  iface.HardwareAddr,
+		}
+		for _, flag := range iface.Flags {
+			if flag == "up" {
+				stats.Up = true
+			}
+		}
+		for _, addr := range iface.Addrs {
+			stats.Addrs = append(stats.Addrs, extractIP(addr.Addr))
+		}
+
+		cur, ok := current[iface.Name]
+		prev, hasPrev := s.prev[iface.Name]
+		if ok && hasPrev && elapsed > 0 {
+			stats.RxBytesPerSec = rate(prev.BytesRecv, cur.BytesRecv, elapsed)
+			stats.TxBytesPerSec = rate(prev.BytesSent, cur.BytesSent, elapsed)
+			stats.RxPacketsPerSec = rate(prev.PacketsRecv, cur.PacketsRecv, elapsed)
+			stats.TxPacketsPerSec = rate(prev.PacketsSent, cur.PacketsSent, elapsed)
+			stats.ErrorsPerSec = rate(prev.Errin+prev.Errout, cur.Errin+cur.Errout, elapsed)
+			stats.DropsPerSec = rate(prev.Dropin+prev.Dropout, cur.Dropin+cur.Dropout, elapsed)
+		}
+
+		total.RxBytesPerSec += stats.RxBytesPerSec
+		total.TxBytesPerSec += stats.TxBytesPerSec
+		total.RxPacketsPerSec += stats.RxPacketsPerSec
+		total.TxPacketsPerSec += stats.TxPacketsPerSec
+		total.ErrorsPerSec += stats.ErrorsPerSec
+		total.DropsPerSec += stats.DropsPerSec
+
+		result = append(result, stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	s.prev = current
+	s.prevTime = now
+
+	return result, total, nil
+}
+
+// rate скорость изменения счетчика; сброс счетчика дает ноль
+func rate(prev, cur uint64, elapsed float64) float64 {
+	if cur < prev {
+		return 0
+	}
+	return float64(cur-prev) / elapsed
+}
@@ -0,0 +1,162 @@
+package teas
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ProcessInfo снимок одного процесса
+type ProcessInfo struct {
+	PID     int32   `json:"pid"`
+	PPID    int32   `json:"ppid"`
+	Name    string  `json:"name"`
+	User    string  `json:"user"`
+	CPU     float64 `json:"cpu"` // % CPU за последний интервал
+	RSS     uint64  `json:"rss"`
+	Threads int32   `json:"threads"`
+	State   string  `json:"state"`
+	Cmdline string  `json:"cmdline"`
+}
+
+// ProcessSampler читает все процессы за один проход и считает CPU % по разнице времени CPU между вызовами
+type ProcessSampler struct {
+	prevCPU  map[int32]float64
+	prevTime time.Time
+}
+
+// NewProcessSampler создает сэмплер; в первом снимке CPU % равен нулю
+func NewProcessSampler() *ProcessSampler {
+	return &ProcessSampler{}
+}
+
+// Sample возвращает список всех процессов
+func (s *ProcessSampler) Sample() ([]ProcessInfo, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(s.prevTime).Seconds()
+	cpuTimes := make(map[int32]float64, len(procs))
+
+	result := make([]ProcessInfo, 0, len(procs))
+	for _, p := range procs {
+		info := ProcessInfo{PID: p.Pid}
+
+		// Процесс мог завершиться во время обхода - пропускаем его
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		info.Name = name
+		info.PPID, _ = p.Ppid()
+		info.User, _ = p.Username()
+		info.Threads, _ = p.NumThreads()
+		info.Cmdline, _ = p.Cmdline()
+		if info.Cmdline == "" {
+			info.Cmdline = "[" + name + "]"
+		}
+		if status, err := p.Status(); err == nil {
+			info.State = strings.Join(status, ",")
+		}
+		if memInfo, err := p.MemoryInfo(); err == nil {
+			info.RSS = memInfo.RSS
+		}
+
+		if times, err := p.Times(); err == nil {
+			busy := times.User + times.System
+			cpuTimes[p.Pid] = busy
+			if prev, ok := s.prevCPU[p.Pid]; ok && elapsed > 0 && busy >= prev {
+				info.CPU = (busy - prev) / elapsed * 100
+			}
+		}
+
+		result = append(result, info)
+	}
+
+	s.prevCPU = cpuTimes
+	s.prevTime = now
+
+	return result, nil
+}
+
+// Колонки таблицы процессов, по которым возможна сортировка
+const (
+	sortByCPU = iota
+	sortByRSS
+	sortByPID
+	sortByPPID
+	sortByUser
+	sortByThreads
+	sortByState
+	sortByCommand
+	sortColumnCount
+)
+
+var sortColumnNames = []string{"CPU%", "RSS", "PID", "PPID", "USER", "THR", "STATE", "COMMAND"}
+
+// sortProcesses сортирует процессы по колонке; по умолчанию самые "тяжелые" сверху
+func sortProcesses(procs []ProcessInfo, column int, reverse bool) {
+	less := func(a, b ProcessInfo) bool {
+		switch column {
+		case sortByRSS:
+			return a.RSS > b.RSS
+		case sortByPID:
+			return a.PID < b.PID
+		case sortByPPID:
+			return a.PPID < b.PPID
+		case sortByUser:
+			return a.User < b.User
+		case sortByThreads:
+			return a.Threads > b.Threads
+		case sortByState:
+			return a.State < b.State
+		case sortByCommand:
+			return a.Cmdline < b.Cmdline
+		default:
+			return a.CPU > b.CPU
+		}
+	}
+
+	sort.SliceStable(procs, func(i, j int) bool {
+		if reverse {
+			return less(procs[j], procs[i])
+		}
+		return less(procs[i], procs[j])
+	})
+}
+
+// filterProcesses оставляет процессы, у которых имя, команда или пользователь содержат подстроку
+func filterProcesses(procs []ProcessInfo, filter string) []ProcessInfo {
+	if filter == "" {
+		return procs
+	}
+
+	filter = strings.ToLower(filter)
+	var result []ProcessInfo
+	for _, p := range procs {
+		if strings.Contains(strings.ToLower(p.Name), filter) ||
+			strings.Contains(strings.ToLower(p.Cmdline), filter) ||
+			strings.Contains(strings.ToLower(p.User), filter) {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
+
+// signalProcess отправляет процессу SIGKILL или SIGTERM
+func signalProcess(pid int32, kill bool) error {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return err
+	}
+	if kill {
+		return p.Kill()
+	}
+	return p.Terminate()
+}
@@ -0,0 +1,191 @@
+package teas
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultProcRows количество строк таблицы процессов, если размер терминала неизвестен
+const defaultProcRows = 20
+
+// visibleProcesses возвращает отфильтрованные и отсортированные процессы
+func (m Model) visibleProcesses() []ProcessInfo {
+	procs := filterProcesses(m.snap.Processes, m.filter)
+	sorted := make([]ProcessInfo, len(procs))
+	copy(sorted, procs)
+	sortProcesses(sorted, m.sortColumn, m.sortReverse)
+	return sorted
+}
+
+// procRows количество строк таблицы процессов, помещающихся на экране
+func (m Model) procRows() int {
+	if m.height > 8 {
+		return m.height - 8
+	}
+	return defaultProcRows
+}
+
+// updateFilter обрабатывает ввод строки фильтра после "/"
+func (m Model) updateFilter(msg tea.KeyMsg) Model {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+	case tea.KeyBackspace:
+		if runes := []rune(m.filter); len(runes) > 0 {
+			m.filter = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	}
+	m.procCursor = 0
+	m.procOffset = 0
+	return m
+}
+
+// updateProcessKeys обрабатывает навигацию, сортировку и сигналы в таблице процессов
+func (m Model) updateProcessKeys(msg tea.KeyMsg) Model {
+	procs := m.visibleProcesses()
+
+	switch msg.String() {
+	case "up":
+		m.procCursor--
+	case "down":
+		m.procCursor++
+	case "pgup":
+		m.procCursor -= m.procRows()
+	case "pgdown":
+		m.procCursor += m.procRows()
+	case "home", "g":
+		m.procCursor = 0
+	case "end", "G":
+		m.procCursor = len(procs) - 1
+	case "s":
+		m.sortColumn = (m.sortColumn + 1) % sortColumnCount
+	case "r":
+		m.sortReverse = !m.sortReverse
+	case "/":
+		m.filtering = true
+	case "t", "K":
+		if m.procCursor >= 0 && m.procCursor < len(procs) {
+			m.signalPID = procs[m.procCursor].PID
+			m.signalKill = msg.String() == "K"
+			m.confirmSignal = true
+		}
+	}
+
+	// Удерживаем курсор в пределах списка и в видимой области
+	if m.procCursor >= len(procs) {
+		m.procCursor = len(procs) - 1
+	}
+	if m.procCursor < 0 {
+		m.procCursor = 0
+	}
+	if m.procCursor < m.procOffset {
+		m.procOffset = m.procCursor
+	}
+	if m.procCursor >= m.procOffset+m.procRows() {
+		m.procOffset = m.procCursor - m.procRows() + 1
+	}
+
+	return m
+}
+
+func (m Model) renderProcesses() string {
+	procs := m.visibleProcesses()
+
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00FFFF")).
+		Bold(true).
+		Render(fmt.Sprintf("Processes: %d shown / %d total | Threads: %d | Sort: %s",
+			len(procs), m.snap.ProcessCount, m.snap.ThreadCount, sortColumnNames[m.sortColumn]))
+	sb.WriteString(title + "\n")
+
+	if m.filtering || m.filter != "" {
+		cursor := ""
+		if m.filtering {
+			cursor = "█"
+		}
+		sb.WriteString(fmt.Sprintf("Filter: %s%s\n", m.filter, cursor))
+	}
+	sb.WriteString("\n")
+
+	header := fmt.Sprintf("  %7s %7s %-10s %6s %9s %4s %-6s %s",
+		"PID", "PPID", "USER", "CPU%", "RSS", "THR", "STATE", "COMMAND")
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(header) + "\n")
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#005F87"))
+
+	end := m.procOffset + m.procRows()
+	if end > len(procs) {
+		end = len(procs)
+	}
+	for i := m.procOffset; i < end; i++ {
+		p := procs[i]
+		row := fmt.Sprintf("%7d %7d %-10s %6.1f %9s %4d %-6s %s",
+			p.PID, p.PPID, truncate(p.User, 10), p.CPU, formatBytes(p.RSS),
+			p.Threads, truncate(p.State, 6), truncate(p.Cmdline, 60))
+
+		if i == m.procCursor {
+			sb.WriteString(selectedStyle.Render("> "+row) + "\n")
+		} else {
+			sb.WriteString("  " + row + "\n")
+		}
+	}
+
+	if m.confirmSignal {
+		signal := "SIGTERM"
+		if m.signalKill {
+			signal = "SIGKILL"
+		}
+		confirm := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFAA00")).
+			Bold(true).
+			Render(fmt.Sprintf("Send %s to process %d? (y/n)", signal, m.signalPID))
+		sb.WriteString("\n" + confirm + "\n")
+	}
+
+	if m.err != "" {
+		sb.WriteString("\n⚠️  " + m.err + "\n")
+	}
+
+	help := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#626262")).
+		Render("↑/↓ pgup/pgdn: move | s: sort column | r: reverse | /: filter | t: SIGTERM | K: SIGKILL | Tab: metrics | q: quit")
+	sb.WriteString("\n" + help)
+
+	return sb.String()
+}
+
+// truncate обрезает строку до заданной длины в символах
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	if limit <= 3 {
+		return string(runes[:limit])
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
+// formatBytes форматирует размер в байтах в человекочитаемый вид
+func formatBytes(b uint64) string {
+	units := []string{"B", "K", "M", "G", "T"}
+	v := float64(b)
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", v, units[i])
+}
@@ -2,101 +2,131 @@ package teas
 
 import (
 	"fmt"
-	"github.com/shirou/gopsutil/v4/process"
 	"strings"
 	"time"
+	"uno/internal/alerts"
 	"uno/internal/table"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/shirou/gopsutil/v4/disk"
-	"github.com/shirou/gopsutil/v4/mem"
 	"github.com/shirou/gopsutil/v4/net"
 )
 
 type Model struct {
-	total        string
-	available    string
-	usedPct      string
-	disk         string
-	totalDisk    string
-	openConns    string
-	macAddress   string
-	bytesSent    string
-	bytesRecv    string
-	ip           string
-	err          string
-	nameP        string
-	statusP      []string
-	usernameP    string
-	cpuP         float64
-	processCount int
-	threadCount  int
+	collector *Collector
+	snap      Snapshot
+	err       string
+	diskLimit float64
+	alerts    *alerts.Engine
+
+	// Таблица процессов
+	showProcs     bool
+	procCursor    int
+	procOffset    int
+	sortColumn    int
+	sortReverse   bool
+	filter        string
+	filtering     bool
+	confirmSignal bool
+	signalKill    bool
+	signalPID     int32
+	height        int
+
+	// История метрик за скользящее окно
+	window   time.Duration
+	ramHist  history
+	cpuHist  history
+	diskHist history // байты, как и "Disk Used"
+	netHist  history
+}
+
+// DefaultWindow окно истории метрик по умолчанию
+const DefaultWindow = 5 * time.Minute
+
+// DefaultDiskThreshold заполненность файловой системы (%), выше которой она подсвечивается
+const DefaultDiskThreshold = 90.0
+
+// sparkWidth ширина спарклайна в таблице
+const sparkWidth = 20
+
+// NewModel создает модель с окном истории заданной длины
+func NewModel(window time.Duration) Model {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	size := int(window / tickInterval)
+
+	return Model{
+		collector: NewCollector(),
+		diskLimit: DefaultDiskThreshold,
+		window:    window,
+		ramHist:   newHistory(size),
+		cpuHist:   newHistory(size),
+		diskHist:  newHistory(size),
+		netHist:   newHistory(size),
+	}
+}
+
+// WithDiskThreshold задает порог заполненности файловых систем для подсветки
+func (m Model) WithDiskThreshold(pct float64) Model {
+	m.diskLimit = pct
+	return m
+}
+
+// WithAlerts включает проверку правил алертинга на каждом тике
+func (m Model) WithAlerts(engine *alerts.Engine) Model {
+	m.alerts = engine
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
 	return tick()
 }
 
-func Process() (string, []string, string, float64) {
-	procs, err := process.Processes()
-	if err != nil {
-		fmt.Println(err)
+// Process returns the top CPU process from a process snapshot
+func Process(procs []ProcessInfo) (string, []string, string, float64) {
+	if len(procs) == 0 {
 		return "N/A", []string{"N/A"}, "N/A", 0
 	}
 
-	var maxCPU float64
-	var topName, topUser string
-	var topStatus []string
-
+	top := procs[0]
 	for _, p := range procs {
-		cpu, err := p.CPUPercent()
-		if err != nil {
-			continue
-		}
-
-		if cpu > maxCPU {
-			name, _ := p.Name()
-			status, _ := p.Status()
-			user, _ := p.Username()
-
-			maxCPU = cpu
-			topName = name
-			topStatus = status
-			topUser = user
+		if p.CPU > top.CPU {
+			top = p
 		}
 	}
 
-	return topName, topStatus, topUser, maxCPU
+	return top.Name, strings.Split(top.State, ","), top.User, top.CPU
 }
-func ProcessSummary() (procCount int, threadCount int, err error) {
-	procs, err := process.Processes()
-	if err != nil {
-		return 0, 0, err
-	}
-
-	procCount = len(procs)
-	threadCount = 0
 
+// ProcessSummary counts processes and threads in a process snapshot
+func ProcessSummary(procs []ProcessInfo) (procCount int, threadCount int) {
 	for _, p := range procs {
-		threads, err := p.NumThreads()
-		if err != nil {
-			continue
-		}
-		threadCount += int(threads)
+		threadCount += int(p.Threads)
 	}
-
-	return procCount, threadCount, nil
+	return len(procs), threadCount
 }
 
-// GetSystemIP gets the first non-loopback IPv4 address
+// GetSystemIP gets all non-loopback addresses (IPv4 and IPv6) joined with commas
 func GetSystemIP() string {
+	ips := GetSystemIPs()
+	if len(ips) == 0 {
+		return "N/A"
+	}
+	return strings.Join(ips, ", ")
+}
+
+// GetSystemIPs gets all non-loopback addresses of all interfaces
+func GetSystemIPs() []string {
 	ifaces, err := net.Interfaces()
 	if err != nil {
-		return "N/A"
+		return nil
 	}
 
+	var ips []string
 	for _, iface := range ifaces {
-		// Skip loopback and down interfaces
+		// Skip loopback interfaces
 		isLoopback := false
 		for _, flag := range iface.Flags {
 			if flag == "loopback" {
@@ -110,13 +140,13 @@ func GetSystemIP() string {
 
 		for _, addr := range iface.Addrs {
 			ip := extractIP(addr.Addr)
-			if isIPv4(ip) && !isLoopbackIP(ip) {
-				return ip
+			if !isLoopbackIP(ip) {
+				ips = append(ips, ip)
 			}
 		}
 	}
 
-	return "N/A"
+	return ips
 }
 
 func extractIP(addr string) string {
@@ -126,156 +156,281 @@ func extractIP(addr string) string {
 	return addr
 }
 
-func isIPv4(ip string) bool {
-	return strings.Count(ip, ".") == 3
-}
-
 func isLoopbackIP(ip string) bool {
-	return strings.HasPrefix(ip, "127.") || ip == "localhost"
+	return strings.HasPrefix(ip, "127.") || ip == "::1" || ip == "localhost"
 }
 
-func CalcDisk() (string, string, error) {
+// CalcDisk returns usage of the root filesystem
+func CalcDisk() (DiskUsage, error) {
 	res, err := disk.Usage("/")
 	if err != nil {
-		return "N/A", "N/A", err
+		return DiskUsage{}, err
 	}
 
-	diskUsage := fmt.Sprintf("%.2f GB", float64(res.Used)/1e9)
-	totalDisk := fmt.Sprintf("%.2f GB", float64(res.Total)/1e9)
-
-	return diskUsage, totalDisk, nil
+	return DiskUsage{Used: res.Used, Total: res.Total, UsedPct: res.UsedPercent}, nil
 }
 
-func CalcNet() (mac, conns, sent, recv, ip string, err error) {
-	ip = GetSystemIP()
+// CalcNet returns open connections, system addresses and per-interface throughput
+func CalcNet(sampler *NetSampler) (NetworkStats, error) {
+	stats := NetworkStats{IPs: GetSystemIPs(), OpenConnections: -1}
 
-	ifaces, err := net.Interfaces()
-	if err != nil {
-		return "N/A", "N/A", "N/A", "N/A", ip, err
-	}
-
-	mac = "N/A"
-	for _, iface := range ifaces {
-		if len(iface.HardwareAddr) > 0 && mac == "N/A" {
-			mac = iface.HardwareAddr
-			break
-		}
+	if connections, err := net.Connections("all"); err == nil {
+		stats.OpenConnections = len(connections)
 	}
 
-	connections, err := net.Connections("all")
-	if err != nil {
-		conns = "N/A"
-	} else {
-		conns = fmt.Sprintf("%d", len(connections))
-	}
-
-	stats, err := net.IOCounters(false)
-	if err != nil || len(stats) == 0 {
-		sent = "N/A"
-		recv = "N/A"
-	} else {
-		sent = fmt.Sprintf("%.2f MB", float64(stats[0].BytesSent)/1e6)
-		recv = fmt.Sprintf("%.2f MB", float64(stats[0].BytesRecv)/1e6)
-	}
-
-	return mac, conns, sent, recv, ip, nil
+	var err error
+	stats.Interfaces, stats.Total, err = sampler.Sample()
+	return stats, err
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
 	case tea.KeyMsg:
+		if m.filtering {
+			return m.updateFilter(msg), nil
+		}
+		if m.confirmSignal {
+			if msg.String() == "y" || msg.String() == "Y" {
+				if err := signalProcess(m.signalPID, m.signalKill); err != nil {
+					m.err = fmt.Sprintf("Signal error: %v", err)
+				}
+			}
+			m.confirmSignal = false
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c", "esc":
 			return m, tea.Quit
+		case "tab", "p":
+			m.showProcs = !m.showProcs
+		}
+		if m.showProcs {
+			m = m.updateProcessKeys(msg)
 		}
 	case tickMsg:
-		// Update memory info
-		v, err := mem.VirtualMemory()
-		if err != nil {
-			m.err = fmt.Sprintf("Memory error: %v", err)
-			return m, tick()
+		if m.collector == nil {
+			m.collector = NewCollector()
 		}
+		m.snap = m.collector.Collect()
+		m.err = strings.Join(m.snap.Errors, "; ")
 
-		// Update disk info
-		diskUsed, diskTotal, err := CalcDisk()
-		if err != nil {
-			m.err = fmt.Sprintf("Disk error: %v", err)
-		}
+		m.ramHist = m.ramHist.add(m.snap.Memory.UsedPct)
+		m.cpuHist = m.cpuHist.add(m.snap.CPU.Total.Total)
+		m.diskHist = m.diskHist.add(float64(m.snap.Disk.Used))
+		m.netHist = m.netHist.add(m.snap.Network.Total.RxBytesPerSec + m.snap.Network.Total.TxBytesPerSec)
+		m.alerts.Evaluate(m.snap.Metrics())
 
-		// Update network info
-		mac, conns, sent, recv, ip, err := CalcNet()
-		if err != nil {
-			m.err = fmt.Sprintf("Network error: %v", err)
-		}
-		nameP, statusP, usernameP, cpuP := Process()
+		return m, tick()
+	}
+	return m, nil
+}
 
-		procCount, threadCount, err := ProcessSummary()
-		if err != nil {
-			m.err = fmt.Sprintf("Process summary error: %v", err)
-		}
+// trend возвращает спарклайн и min/avg/max метрики за окно
+func trend(h history, format func(float64) string) []string {
+	minV, avgV, maxV := h.stats()
+	return []string{
+		table.Sparkline(h.buckets(sparkWidth), sparkWidth),
+		fmt.Sprintf("%s / %s / %s", format(minV), format(avgV), format(maxV)),
+	}
+}
 
-		// Update model fields
-		m.total = fmt.Sprintf("%.2f GB", float64(v.Total)/1e9)
-		m.available = fmt.Sprintf("%.2f GB", float64(v.Available)/1e9)
-		m.usedPct = fmt.Sprintf("%.2f %%", v.UsedPercent)
-		m.disk = diskUsed
-		m.totalDisk = diskTotal
-		m.macAddress = mac
-		m.openConns = conns
-		m.bytesSent = sent
-		m.bytesRecv = recv
-		m.ip = ip
-		m.nameP = nameP
-		m.usernameP = usernameP
-		m.cpuP = cpuP
-		m.statusP = statusP
-		m.processCount = procCount
-		m.threadCount = threadCount
+func formatGB(b uint64) string {
+	return fmt.Sprintf("%.2f GB", float64(b)/1e9)
+}
 
-		return m, tick()
+// formatBytesGB formatGB для значений истории, которая хранит байты как float64
+func formatBytesGB(v float64) string {
+	return formatGB(uint64(v))
+}
+
+func formatPct(v float64) string {
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+func formatRate(v float64) string {
+	units := []string{"B", "K", "M", "G"}
+	i := 0
+	for v >= 1000 && i < len(units)-1 {
+		v /= 1000
+		i++
 	}
-	return m, nil
+	return fmt.Sprintf("%.1f%s/s", v, units[i])
 }
 
 func (m Model) View() string {
+	if m.showProcs {
+		return m.alerts.Banner() + m.renderProcesses()
+	}
+
+	snap := m.snap
+	ip := "N/A"
+	if len(snap.Network.IPs) > 0 {
+		ip = strings.Join(snap.Network.IPs, "\n")
+	}
+	conns := "N/A"
+	if snap.Network.OpenConnections >= 0 {
+		conns = fmt.Sprintf("%d", snap.Network.OpenConnections)
+	}
+
 	data := [][]string{
-		{"Total RAM", m.total},
-		{"Available RAM", m.available},
-		{"Used RAM", m.usedPct},
+		{"Total RAM", formatGB(snap.Memory.Total)},
+		{"Available RAM", formatGB(snap.Memory.Available)},
+		append([]string{"Used RAM", fmt.Sprintf("%.2f %%", snap.Memory.UsedPct)}, trend(m.ramHist, formatPct)...),
+		append([]string{"CPU Total", formatPct(snap.CPU.Total.Total)}, trend(m.cpuHist, formatPct)...),
 		{"────────────────────────", ""},
-		{"Disk Used", m.disk},
-		{"Total Disk", m.totalDisk},
+		append([]string{"Disk Used", formatGB(snap.Disk.Used)}, trend(m.diskHist, formatBytesGB)...),
+		{"Total Disk", formatGB(snap.Disk.Total)},
 		{"────────────────────────", ""},
-		{"IP Address", m.ip},
-		{"MAC Address", m.macAddress},
-		{"Open Connections", m.openConns},
-		{"Bytes Sent", m.bytesSent},
-		{"Bytes Received", m.bytesRecv},
+		{"IP Address", ip},
+		{"Open Connections", conns},
+		append([]string{"Network Traffic", formatRate(snap.Network.Total.RxBytesPerSec + snap.Network.Total.TxBytesPerSec)}, trend(m.netHist, formatRate)...),
 		{"────────────────────────", ""},
-		{"Top Process", m.nameP},
-		{"Process CPU", fmt.Sprintf("%.2f %%", m.cpuP)},
-		{"Process Status", strings.Join(m.statusP, ", ")},
-		{"Process User", m.usernameP},
+		{"Top Process", snap.TopProcess.Name},
+		{"Process CPU", fmt.Sprintf("%.2f %%", snap.TopProcess.CPU)},
+		{"Process Status", strings.Join(snap.TopProcess.Status, ", ")},
+		{"Process User", snap.TopProcess.User},
 		{"────────────────────────", ""},
-		{"Total Processes", fmt.Sprintf("%d", m.processCount)},
-		{"Total Threads", fmt.Sprintf("%d", m.threadCount)},
+		{"Total Processes", fmt.Sprintf("%d", snap.ProcessCount)},
+		{"Total Threads", fmt.Sprintf("%d", snap.ThreadCount)},
 	}
 
-	view := table.RenderTable(data)
+	header := []string{"metrics", "value", "trend " + m.window.String(), "min / avg / max"}
+	view := m.alerts.Banner() + table.RenderTableWithHeader(header, data)
+	view += "\n" + m.renderCPUPanel()
+	view += "\n" + m.renderDisks()
+	view += "\n" + m.renderInterfaces()
 
 	if m.err != "" {
 		view += "\n⚠️  " + m.err
 	}
 
-	view += "\n\nPress Q/Ctrl+C/Esc to quit..."
+	view += "\n\nPress Tab/P for processes, Q/Ctrl+C/Esc to quit..."
+
+	return view
+}
+
+// renderCPUPanel рисует загрузку по ядрам, load average и подкачку в виде полос
+func (m Model) renderCPUPanel() string {
+	var sb strings.Builder
+	cs := m.snap.CPU
+
+	usageLine := func(u CPUUsage) string {
+		return fmt.Sprintf("%-6s %s %5.1f%%  usr %5.1f  sys %5.1f  io %5.1f  st %5.1f\n",
+			u.Name, bar(u.Total, 25), u.Total, u.User, u.System, u.Iowait, u.Steal)
+	}
+
+	sb.WriteString(usageLine(cs.Total))
+	for _, core := range cs.Cores {
+		sb.WriteString(usageLine(core))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nLoad average: %.2f %.2f %.2f | Context switches: %.0f/s | Interrupts: %.0f/s\n",
+		cs.Load1, cs.Load5, cs.Load15, cs.CtxSwitchesPerSec, cs.InterruptsPerSec))
+	sb.WriteString(fmt.Sprintf("%-6s %s %5.1f%%  %s / %s  in %s  out %s\n",
+		"swap", bar(cs.SwapUsedPct, 25), cs.SwapUsedPct,
+		formatBytes(cs.SwapUsed), formatBytes(cs.SwapTotal),
+		formatRate(cs.SwapInPerSec), formatRate(cs.SwapOutPerSec)))
+
+	return sb.String()
+}
+
+// bar рисует полосу заполнения для значения в процентах
+func bar(pct float64, width int) string {
+	filled := int(pct / 100 * float64(width))
+	if filled > width {
+		filled = width
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// renderDisks рисует файловые системы и нагрузку на устройства; заполненные выше порога подсвечиваются
+func (m Model) renderDisks() string {
+	warn := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true).Render
+
+	var mounts [][]string
+	for _, mnt := range m.snap.Mounts {
+		row := []string{
+			mnt.Mountpoint,
+			mnt.Device,
+			mnt.Fstype,
+			formatBytes(mnt.Used),
+			formatBytes(mnt.Free),
+			formatBytes(mnt.Total),
+			formatPct(mnt.UsedPct),
+			formatPct(mnt.InodesPct),
+		}
+		if mnt.UsedPct >= m.diskLimit || mnt.InodesPct >= m.diskLimit {
+			for i := range row {
+				row[i] = warn(row[i])
+			}
+		}
+		mounts = append(mounts, row)
+	}
+	view := table.RenderTableWithHeader(
+		[]string{"mount", "device", "fs", "used", "free", "total", "use%", "inodes%"}, mounts)
+
+	var devices [][]string
+	for _, dev := range m.snap.DiskIO {
+		devices = append(devices, []string{
+			dev.Name,
+			formatRate(dev.ReadBytesPerSec),
+			formatRate(dev.WriteBytesPerSec),
+			fmt.Sprintf("%.0f / %.0f", dev.ReadIOPS, dev.WriteIOPS),
+			formatPct(dev.UtilPct),
+		})
+	}
+	if len(devices) > 0 {
+		view += "\n" + table.RenderTableWithHeader(
+			[]string{"device", "read", "write", "iops r / w", "util%"}, devices)
+	}
 
 	return view
 }
 
+// renderInterfaces рисует таблицу сетевых интерфейсов с итоговой строкой
+func (m Model) renderInterfaces() string {
+	header := []string{"interface", "addresses", "mac", "state", "rx / tx", "pkts rx / tx", "err / drop"}
+
+	row := func(iface InterfaceStats) []string {
+		state := "down"
+		if iface.Up {
+			state = "up"
+		}
+		return []string{
+			iface.Name,
+			strings.Join(iface.Addrs, "\n"),
+			iface.MAC,
+			state,
+			fmt.Sprintf("%s / %s", formatRate(iface.RxBytesPerSec), formatRate(iface.TxBytesPerSec)),
+			fmt.Sprintf("%.0f / %.0f", iface.RxPacketsPerSec, iface.TxPacketsPerSec),
+			fmt.Sprintf("%.1f / %.1f", iface.ErrorsPerSec, iface.DropsPerSec),
+		}
+	}
+
+	var data [][]string
+	for _, iface := range m.snap.Network.Interfaces {
+		data = append(data, row(iface))
+	}
+	total := row(m.snap.Network.Total)
+	total[3] = ""
+	data = append(data, total)
+
+	return table.RenderTableWithHeader(header, data)
+}
+
 type tickMsg time.Time
 
+// tickInterval период обновления метрик
+const tickInterval = time.Second
+
 func tick() tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+	return tea.Tick(tickInterval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }